@@ -0,0 +1,168 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ghchinoy/drivectl/internal/drive"
+	"github.com/ghchinoy/drivectl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditInternalDomains []string
+	auditFormat          string
+	auditOutputFile      string
+)
+
+var auditCmd = &cobra.Command{
+	Use:     "audit",
+	GroupID: GroupAdvanced,
+	Short:   "Audit Google Drive content",
+	Long:    `A set of commands that scan Google Drive content and produce review reports.`,
+}
+
+var auditSharingCmd = &cobra.Command{
+	Use:   "sharing <folderId>",
+	Short: "Reports risky sharing across a folder tree.",
+	Long: `Walks every file under a folder and flags permissions that grant access to external
+domains or users, public ("anyone") access with or without the link, and permissions of accounts
+that have been deleted, including files whose owner was deleted. Drive does not report whether an
+account is suspended, so owners who are suspended or otherwise offboarded are not flagged.
+
+By default, the domain of the signed-in account is treated as internal. Use --internal-domain to
+list the company's domains explicitly.`,
+	Example: `  drivectl audit sharing <folder-id>
+  drivectl audit sharing <folder-id> --internal-domain example.com --internal-domain example.org
+  drivectl audit sharing <folder-id> --format csv -o sharing.csv
+  drivectl audit sharing <folder-id> --format md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domains := auditInternalDomains
+		if len(domains) == 0 {
+			email, err := drive.CurrentUserEmail(driveSvc)
+			if err != nil {
+				return ui.ErrorWithHint(err, "Pass --internal-domain to skip looking up the signed-in account.")
+			}
+			if _, domain, ok := strings.Cut(email, "@"); ok {
+				domains = []string{domain}
+			}
+		}
+
+		audit, err := drive.AuditSharing(driveSvc, args[0], domains)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the folder ID is correct and you have permission to view it.")
+		}
+
+		reportFormat := auditFormat
+		if OutputFormat == "json" {
+			reportFormat = "json"
+		}
+
+		var report []byte
+		switch reportFormat {
+		case "json":
+			report, err = json.MarshalIndent(audit, "", "  ")
+		case "csv":
+			report, err = sharingAuditCSV(audit)
+		case "md", "markdown":
+			report = sharingAuditMarkdown(audit)
+		case "":
+			printSharingAudit(audit)
+			return nil
+		default:
+			return fmt.Errorf("invalid format: %s. Valid formats are: csv, json, md", auditFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		if auditOutputFile != "" {
+			if err := os.WriteFile(auditOutputFile, report, 0644); err != nil {
+				return ui.ErrorWithHint(fmt.Errorf("failed to write to output file %s: %w", auditOutputFile, err), "Check file path permissions.")
+			}
+			ui.PrintSuccess("Saved report to %s", auditOutputFile)
+			return nil
+		}
+		fmt.Println(strings.TrimRight(string(report), "\n"))
+		return nil
+	},
+}
+
+func sharingAuditCSV(audit *drive.SharingAudit) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"path", "fileId", "mimeType", "issue", "type", "target", "role", "link"})
+	for _, f := range audit.Findings {
+		_ = w.Write([]string{f.Path, f.FileID, f.MimeType, f.Issue, f.Type, f.Target, f.Role, f.Link})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func sharingAuditMarkdown(audit *drive.SharingAudit) []byte {
+	var b strings.Builder
+	b.WriteString("# Sharing Audit\n\n")
+	fmt.Fprintf(&b, "- Folder: `%s`\n", audit.FolderID)
+	fmt.Fprintf(&b, "- Internal domains: %s\n", strings.Join(audit.InternalDomains, ", "))
+	fmt.Fprintf(&b, "- Files scanned: %d\n", audit.FilesScanned)
+	fmt.Fprintf(&b, "- Findings: %d\n\n", len(audit.Findings))
+	if len(audit.Findings) == 0 {
+		b.WriteString("No risky sharing found.\n")
+		return []byte(b.String())
+	}
+	b.WriteString("| Path | Issue | Type | Target | Role |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, f := range audit.Findings {
+		path := escapeMarkdownCell(f.Path)
+		if f.Link != "" {
+			path = fmt.Sprintf("[%s](%s)", path, f.Link)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", path, f.Issue, f.Type, escapeMarkdownCell(f.Target), f.Role)
+	}
+	return []byte(b.String())
+}
+
+// escapeMarkdownCell makes a value safe to place inside a Markdown table cell.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+func printSharingAudit(audit *drive.SharingAudit) {
+	fmt.Println(ui.Accent(fmt.Sprintf("Sharing audit for %s:", audit.FolderID)))
+	fmt.Println(ui.Muted(fmt.Sprintf("Scanned %d files; internal domains: %s", audit.FilesScanned, strings.Join(audit.InternalDomains, ", "))))
+	if len(audit.Findings) == 0 {
+		fmt.Println(ui.Pass("No risky sharing found."))
+		return
+	}
+	for _, f := range audit.Findings {
+		fmt.Printf("%s %s %s %s (%s)\n", ui.Warn(f.Issue), f.Path, ui.ID("("+f.FileID+")"), f.Target, f.Role)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditSharingCmd)
+	auditSharingCmd.Flags().StringSliceVar(&auditInternalDomains, "internal-domain", nil, "Domain considered internal (repeatable; defaults to the signed-in account's domain)")
+	auditSharingCmd.Flags().StringVar(&auditFormat, "format", "", "Report format (csv, json, md)")
+	auditSharingCmd.Flags().StringVarP(&auditOutputFile, "output", "o", "", "Path to save the report")
+}
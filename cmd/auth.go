@@ -13,6 +13,10 @@ import (
 	"github.com/spf13/viper"
 )
 
+// reloginHint ends the hints of commands that change files, whose failures may
+// come from a login made before drivectl needed write access.
+const reloginHint = "If your login predates write support, run 'drivectl auth login' again."
+
 var authCmd = &cobra.Command{
 	Use:     "auth",
 	GroupID: GroupAuth,
@@ -0,0 +1,218 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/ghchinoy/drivectl/internal/drive"
+	"github.com/ghchinoy/drivectl/internal/ui"
+	"github.com/spf13/cobra"
+	googledrive "google.golang.org/api/drive/v3"
+)
+
+var (
+	changesProfile  string
+	changesFolder   string
+	changesMimeType string
+	changesInterval time.Duration
+)
+
+var changesCmd = &cobra.Command{
+	Use:     "changes",
+	GroupID: GroupCore,
+	Short:   "Shows files changed since the previous run.",
+	Long: `Uses the Google Drive Changes API to report files that were added, modified or removed
+since the last time this command ran. The change log position is saved per profile in the
+drivectl config directory. The first run only records the starting point.
+With --folder or --mime-type, the files that match are remembered between runs, so a file
+removed from the folder is still reported even though the removal carries no metadata. Files
+in the folder when the starting point is recorded are remembered from the start; with
+--mime-type alone, a file is only remembered once a change to it has been seen.`,
+	Example: `  drivectl changes
+  drivectl changes --folder <folder-id>
+  drivectl changes --mime-type application/vnd.google-apps.document -O json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := drive.LoadChangeState(changeStateKey())
+		if err != nil {
+			return err
+		}
+
+		if state == nil {
+			state, err = startChangeState()
+			if err != nil {
+				return err
+			}
+			if OutputFormat == "json" {
+				fmt.Println("[]")
+				return nil
+			}
+			ui.PrintSuccess("Recorded starting point for profile %s. Run again to see changes.", changesProfile)
+			return nil
+		}
+
+		events, err := pollChanges(state)
+		if err != nil {
+			return err
+		}
+
+		if OutputFormat == "json" {
+			if events == nil {
+				events = []*drive.ChangeEvent{}
+			}
+			b, err := json.MarshalIndent(events, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		fmt.Println(ui.Accent("Changes:"))
+		if len(events) == 0 {
+			fmt.Println(ui.Muted("No changes found."))
+			return nil
+		}
+		for _, e := range events {
+			label := ui.Warn(e.Type)
+			switch e.Type {
+			case "added":
+				label = ui.Pass(e.Type)
+			case "removed":
+				label = ui.Fail(e.Type)
+			}
+			fmt.Printf("%s %s %s %s\n", label, e.Name, ui.ID("("+e.FileID+")"), ui.Muted(e.Time))
+		}
+		return nil
+	},
+}
+
+var changesWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Polls for changes and emits them as NDJSON.",
+	Long: `Polls the Google Drive Changes API at a fixed interval and writes one JSON object per line
+for every change, suitable for piping into other tools. Runs until interrupted.`,
+	Example: `  drivectl changes watch --interval 30s
+  drivectl changes watch --folder <folder-id> | jq .name`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if changesInterval <= 0 {
+			return fmt.Errorf("invalid interval: %s. The interval must be positive", changesInterval)
+		}
+		state, err := drive.LoadChangeState(changeStateKey())
+		if err != nil {
+			return err
+		}
+		if state == nil {
+			state, err = startChangeState()
+			if err != nil {
+				return err
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		enc := json.NewEncoder(os.Stdout)
+		ticker := time.NewTicker(changesInterval)
+		defer ticker.Stop()
+		for {
+			events, err := pollChanges(state)
+			if err != nil {
+				return err
+			}
+			for _, e := range events {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// changeStateKey returns the key the change log position is saved under.
+// Each shared drive has its own change log, so its ID is part of the key.
+func changeStateKey() string {
+	if id := drive.CurrentScope().DriveID; id != "" {
+		return changesProfile + "@" + id
+	}
+	return changesProfile
+}
+
+// startChangeState records the current head of the change log for the active profile.
+func startChangeState() (*drive.ChangeState, error) {
+	token, err := drive.GetStartPageToken(driveSvc)
+	if err != nil {
+		return nil, err
+	}
+	state := &drive.ChangeState{
+		PageToken: token,
+		LastRun:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if changesFolder != "" {
+		children, err := drive.ListChildren(driveSvc, changesFolder)
+		if err != nil {
+			return nil, err
+		}
+		state.Remember(slices.DeleteFunc(children, func(f *googledrive.File) bool {
+			return changesMimeType != "" && f.MimeType != changesMimeType
+		}))
+	}
+	if err := drive.SaveChangeState(changeStateKey(), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// pollChanges fetches changes since state, advances state and saves it.
+func pollChanges(state *drive.ChangeState) ([]*drive.ChangeEvent, error) {
+	changes, next, err := drive.ListChanges(driveSvc, state.PageToken)
+	if err != nil {
+		return nil, ui.ErrorWithHint(err, "If the saved page token has expired, delete ~/.config/drivectl/changes.json to start over.")
+	}
+
+	since, _ := time.Parse(time.RFC3339, state.LastRun)
+	events := drive.ClassifyChanges(changes, since, drive.ChangeFilter{
+		FolderID: changesFolder,
+		MimeType: changesMimeType,
+	}, state)
+
+	state.PageToken = next
+	state.LastRun = time.Now().UTC().Format(time.RFC3339)
+	if err := drive.SaveChangeState(changeStateKey(), state); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func init() {
+	rootCmd.AddCommand(changesCmd)
+	changesCmd.AddCommand(changesWatchCmd)
+	changesCmd.PersistentFlags().StringVar(&changesProfile, "profile", "default", "Name under which the change log position is saved")
+	changesCmd.PersistentFlags().StringVar(&changesFolder, "folder", "", "Only report files directly inside this folder ID")
+	changesCmd.PersistentFlags().StringVar(&changesMimeType, "mime-type", "", "Only report files with this MIME type")
+	changesWatchCmd.Flags().DurationVar(&changesInterval, "interval", 30*time.Second, "Polling interval")
+}
@@ -15,25 +15,77 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghchinoy/drivectl/internal/drive"
 	"github.com/ghchinoy/drivectl/internal/ui"
 	"github.com/spf13/cobra"
+	googledrive "google.golang.org/api/drive/v3"
 )
 
+var (
+	commentsUnresolved bool
+	commentsAuthor     string
+	commentsSince      string
+	commentQuote       string
+	commentMessage     string
+	commentReplyId     string
+	commentsFormat     string
+	commentsOutputFile string
+)
+
+// parseSince accepts an RFC 3339 timestamp, a date such as 2025-01-31, a Go
+// duration such as 48h, or a number of days such as 7d, the latter two counted back from now.
+func parseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().Add(-time.Duration(n) * 24 * time.Hour), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use an RFC 3339 time, a date like 2025-01-31, a duration like 48h, or days like 7d", s)
+	}
+	return time.Now().Add(-d), nil
+}
+
 var commentsCmd = &cobra.Command{
 	Use:     "comments <file-id>",
 	GroupID: GroupCore,
 	Short:   "Lists comments for a Google Drive file or Google Doc",
-	Long:    `Retrieves and displays the comment history for a given file ID from the Google Drive API.`,
-	Example: `  drivectl comments <file-id>`,
-	Args:    cobra.ExactArgs(1),
+	Long: `Retrieves and displays the comment history for a given file ID from the Google Drive API.
+Use the subcommands to add comments, reply to, resolve, reopen or delete comment threads.`,
+	Example: `  drivectl comments <file-id>
+  drivectl comments <file-id> --unresolved --since 7d
+  drivectl comments <file-id> --author alice@example.com`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fileId := args[0]
-		comments, err := drive.ListComments(driveSvc, fileId)
+		since, err := parseSince(commentsSince)
+		if err != nil {
+			return err
+		}
+		comments, err := drive.ListComments(driveSvc, fileId, drive.CommentFilter{
+			Unresolved: commentsUnresolved,
+			Author:     commentsAuthor,
+			Since:      since,
+		})
 		if err != nil {
 			hint := "Check if the file ID is correct and you have permission to view comments."
 			if strings.Contains(err.Error(), "500") || strings.Contains(err.Error(), "403") {
@@ -70,7 +122,11 @@ var commentsCmd = &cobra.Command{
 			}
 			
 			// Display the main comment
-			fmt.Printf("\n[%s] %s\n", ui.Muted(comment.CreatedTime), ui.ID(author))
+			state := ""
+			if comment.Resolved {
+				state = " " + ui.Pass("(resolved)")
+			}
+			fmt.Printf("\n[%s] %s %s%s\n", ui.Muted(comment.CreatedTime), ui.ID(author), ui.Muted(comment.Id), state)
 			if comment.QuotedFileContent != nil && comment.QuotedFileContent.Value != "" {
 				fmt.Printf("%s\n", ui.Muted(fmt.Sprintf("  > %s", comment.QuotedFileContent.Value)))
 			}
@@ -90,6 +146,273 @@ var commentsCmd = &cobra.Command{
 	},
 }
 
+// printCommentResult prints the comment or reply returned by a write operation.
+func printCommentResult(v interface{}, msg string, args ...interface{}) error {
+	if OutputFormat == "json" {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	ui.PrintSuccess(msg, args...)
+	return nil
+}
+
+const commentWriteHint = "Ensure the IDs are correct and you can comment on this file. " + reloginHint
+
+var commentsAddCmd = &cobra.Command{
+	Use:   "add <file-id> <text>",
+	Short: "Posts a new comment on a file.",
+	Long: `Posts a new comment on a file. Use --quote to attach the comment to a passage of text
+from the file, which is shown alongside the comment.`,
+	Example: `  drivectl comments add <file-id> "Looks good to me"
+  drivectl comments add <file-id> "Should this be 2025?" --quote "launch in 2024"`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		comment, err := drive.CreateComment(driveSvc, args[0], args[1], commentQuote)
+		if err != nil {
+			return ui.ErrorWithHint(err, commentWriteHint)
+		}
+		return printCommentResult(comment, "Posted comment %s", ui.ID(comment.Id))
+	},
+}
+
+var commentsReplyCmd = &cobra.Command{
+	Use:     "reply <file-id> <comment-id> <text>",
+	Short:   "Replies to a comment thread.",
+	Long:    `Adds a reply to an existing comment thread.`,
+	Example: `  drivectl comments reply <file-id> <comment-id> "Fixed in the latest draft"`,
+	Args:    cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reply, err := drive.ReplyToComment(driveSvc, args[0], args[1], args[2], "")
+		if err != nil {
+			return ui.ErrorWithHint(err, commentWriteHint)
+		}
+		return printCommentResult(reply, "Posted reply %s", ui.ID(reply.Id))
+	},
+}
+
+var commentsResolveCmd = &cobra.Command{
+	Use:     "resolve <file-id> <comment-id>",
+	Short:   "Resolves a comment thread.",
+	Long:    `Marks a comment thread as resolved, optionally with a closing message.`,
+	Example: `  drivectl comments resolve <file-id> <comment-id> --message "Done"`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reply, err := drive.ReplyToComment(driveSvc, args[0], args[1], commentMessage, "resolve")
+		if err != nil {
+			return ui.ErrorWithHint(err, commentWriteHint)
+		}
+		return printCommentResult(reply, "Resolved comment %s", ui.ID(args[1]))
+	},
+}
+
+var commentsReopenCmd = &cobra.Command{
+	Use:     "reopen <file-id> <comment-id>",
+	Short:   "Reopens a resolved comment thread.",
+	Long:    `Reopens a previously resolved comment thread, optionally with a message.`,
+	Example: `  drivectl comments reopen <file-id> <comment-id> --message "This regressed"`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reply, err := drive.ReplyToComment(driveSvc, args[0], args[1], commentMessage, "reopen")
+		if err != nil {
+			return ui.ErrorWithHint(err, commentWriteHint)
+		}
+		return printCommentResult(reply, "Reopened comment %s", ui.ID(args[1]))
+	},
+}
+
+var commentsDeleteCmd = &cobra.Command{
+	Use:   "delete <file-id> <comment-id>",
+	Short: "Deletes a comment thread or reply.",
+	Long:  `Deletes an entire comment thread, or only a single reply within it when --reply is given.`,
+	Example: `  drivectl comments delete <file-id> <comment-id>
+  drivectl comments delete <file-id> <comment-id> --reply <reply-id>`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := drive.DeleteComment(driveSvc, args[0], args[1], commentReplyId); err != nil {
+			return ui.ErrorWithHint(err, commentWriteHint)
+		}
+		res := map[string]interface{}{
+			"status":    "deleted",
+			"fileId":    args[0],
+			"commentId": args[1],
+			"replyId":   commentReplyId,
+		}
+		if commentReplyId != "" {
+			return printCommentResult(res, "Deleted reply %s", ui.ID(commentReplyId))
+		}
+		return printCommentResult(res, "Deleted comment %s", ui.ID(args[1]))
+	},
+}
+
+var commentsExportCmd = &cobra.Command{
+	Use:   "export <fileId|folderId>",
+	Short: "Exports comment threads as a review report.",
+	Long: `Renders every comment thread on a file with its quoted text, author, timestamps, replies
+and resolution state, as Markdown, CSV or JSON.
+
+When given a folder, the report aggregates the unresolved comments of every Google Doc in the
+folder tree, leaving out Docs with no open feedback.`,
+	Example: `  drivectl comments export <file-id> --format md
+  drivectl comments export <file-id> --format csv -o comments.csv
+  drivectl comments export <folder-id> --format md -o open-feedback.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parseSince(commentsSince)
+		if err != nil {
+			return err
+		}
+		docs, err := drive.CollectComments(driveSvc, args[0], drive.CommentFilter{
+			Unresolved: commentsUnresolved,
+			Author:     commentsAuthor,
+			Since:      since,
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the ID is correct and you have permission to view it.")
+		}
+
+		reportFormat := commentsFormat
+		if OutputFormat == "json" {
+			reportFormat = "json"
+		}
+
+		var report []byte
+		switch reportFormat {
+		case "json":
+			report, err = json.MarshalIndent(docs, "", "  ")
+		case "csv":
+			report, err = commentsCSV(docs)
+		case "md", "markdown":
+			report = commentsMarkdown(docs)
+		default:
+			return fmt.Errorf("invalid format: %s. Valid formats are: csv, json, md", commentsFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		if commentsOutputFile != "" {
+			if err := os.WriteFile(commentsOutputFile, report, 0644); err != nil {
+				return ui.ErrorWithHint(fmt.Errorf("failed to write to output file %s: %w", commentsOutputFile, err), "Check file path permissions.")
+			}
+			ui.PrintSuccess("Saved report to %s", commentsOutputFile)
+			return nil
+		}
+		fmt.Println(strings.TrimRight(string(report), "\n"))
+		return nil
+	},
+}
+
+// commentAuthor returns the display name and email of a comment's author.
+func commentAuthor(u *googledrive.User) (string, string) {
+	if u == nil {
+		return "Unknown", ""
+	}
+	return u.DisplayName, u.EmailAddress
+}
+
+// commentsCSV writes one row per comment and per reply, so a spreadsheet can
+// filter and group the threads by their comment ID.
+func commentsCSV(docs []*drive.DocumentComments) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"path", "fileId", "commentId", "replyId", "author", "email", "createdTime", "modifiedTime", "resolved", "action", "quote", "content"})
+	for _, d := range docs {
+		for _, c := range d.Comments {
+			name, email := commentAuthor(c.Author)
+			quote := ""
+			if c.QuotedFileContent != nil {
+				quote = c.QuotedFileContent.Value
+			}
+			resolved := strconv.FormatBool(c.Resolved)
+			_ = w.Write([]string{d.Path, d.FileID, c.Id, "", name, email, c.CreatedTime, c.ModifiedTime, resolved, "", quote, c.Content})
+			for _, r := range c.Replies {
+				name, email := commentAuthor(r.Author)
+				_ = w.Write([]string{d.Path, d.FileID, c.Id, r.Id, name, email, r.CreatedTime, r.ModifiedTime, resolved, r.Action, "", r.Content})
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// replyActionLabels describes the reply actions that change the state of a
+// comment thread.
+var replyActionLabels = map[string]string{
+	"resolve": "resolved",
+	"reopen":  "reopened",
+}
+
+func commentsMarkdown(docs []*drive.DocumentComments) []byte {
+	var b strings.Builder
+	b.WriteString("# Comment Review\n")
+	if len(docs) == 0 {
+		b.WriteString("\nNo comments found.\n")
+		return []byte(b.String())
+	}
+	for _, d := range docs {
+		title := d.Path
+		if d.Link != "" {
+			title = fmt.Sprintf("[%s](%s)", d.Path, d.Link)
+		}
+		fmt.Fprintf(&b, "\n## %s\n", title)
+		if len(d.Comments) == 0 {
+			b.WriteString("\nNo comments found.\n")
+			continue
+		}
+		for _, c := range d.Comments {
+			state := "Open"
+			if c.Resolved {
+				state = "Resolved"
+			}
+			name, email := commentAuthor(c.Author)
+			if email != "" {
+				name = fmt.Sprintf("%s (%s)", name, email)
+			}
+			fmt.Fprintf(&b, "\n### %s, %s: %s\n\n", name, c.CreatedTime, state)
+			if c.QuotedFileContent != nil && c.QuotedFileContent.Value != "" {
+				fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(c.QuotedFileContent.Value, "\n", "\n> "))
+			}
+			b.WriteString(c.Content + "\n")
+			for _, r := range c.Replies {
+				name, _ := commentAuthor(r.Author)
+				text := r.Content
+				if label, ok := replyActionLabels[r.Action]; ok {
+					text = strings.TrimSpace(fmt.Sprintf("*%s* %s", label, text))
+				}
+				fmt.Fprintf(&b, "\n- **%s** (%s): %s", name, r.CreatedTime, strings.ReplaceAll(text, "\n", " "))
+			}
+			if len(c.Replies) > 0 {
+				b.WriteString("\n")
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
 func init() {
 	rootCmd.AddCommand(commentsCmd)
+	commentsCmd.AddCommand(commentsExportCmd)
+	commentsCmd.AddCommand(commentsAddCmd)
+	commentsCmd.AddCommand(commentsReplyCmd)
+	commentsCmd.AddCommand(commentsResolveCmd)
+	commentsCmd.AddCommand(commentsReopenCmd)
+	commentsCmd.AddCommand(commentsDeleteCmd)
+
+	commentsCmd.Flags().BoolVar(&commentsUnresolved, "unresolved", false, "Only show unresolved comment threads")
+	commentsCmd.Flags().StringVar(&commentsAuthor, "author", "", "Only show comments whose author name or email contains this value")
+	commentsCmd.Flags().StringVar(&commentsSince, "since", "", "Only show comments modified since this time (RFC 3339, YYYY-MM-DD, 48h or 7d)")
+
+	commentsAddCmd.Flags().StringVar(&commentQuote, "quote", "", "Text from the file that the comment refers to")
+	commentsResolveCmd.Flags().StringVar(&commentMessage, "message", "", "Message to post when resolving")
+	commentsReopenCmd.Flags().StringVar(&commentMessage, "message", "", "Message to post when reopening")
+	commentsExportCmd.Flags().StringVar(&commentsFormat, "format", "md", "Report format (md, csv, json)")
+	commentsExportCmd.Flags().StringVarP(&commentsOutputFile, "output", "o", "", "Path to save the report")
+	commentsExportCmd.Flags().BoolVar(&commentsUnresolved, "unresolved", false, "Only include unresolved comment threads (always on for folders)")
+	commentsExportCmd.Flags().StringVar(&commentsAuthor, "author", "", "Only include comments whose author name or email contains this value")
+	commentsExportCmd.Flags().StringVar(&commentsSince, "since", "", "Only include comments modified since this time (RFC 3339, YYYY-MM-DD, 48h or 7d)")
+	commentsDeleteCmd.Flags().StringVar(&commentReplyId, "reply", "", "Delete only this reply instead of the whole thread")
 }
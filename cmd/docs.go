@@ -17,14 +17,42 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/ghchinoy/drivectl/internal/diff"
 	"github.com/ghchinoy/drivectl/internal/drive"
 	"github.com/ghchinoy/drivectl/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	docsSpreadsheet string
+	docsSheet       string
+	docsRange       string
+	docsTabId       string
+	docsIndex       int64
+	docsTableHeader string
+	docsRender      string
+	docsData        string
+	docsName        string
+	docsTarget      string
+	docsPDFDir      string
+	docsIDColumn    string
+	docsDryRun      bool
+	docsFind        string
+	docsReplace     string
+	docsRegex       bool
+	docsMatchCase   bool
+	docsVars        string
+	docsOutput      string
+	docsWordDiff    bool
+	docsContext     int
+)
+
 var docsCmd = &cobra.Command{
 	Use:     "docs",
 	GroupID: GroupIntegration,
@@ -108,8 +136,461 @@ var docsCreateCmd = &cobra.Command{
 	},
 }
 
+var docsInsertTableCmd = &cobra.Command{
+	Use:   "insert-table <documentId>",
+	Short: "Inserts a range from a Google Sheet into a Google Doc as a table.",
+	Long: `Reads a range from a spreadsheet and inserts it into a Google Doc as a native table, at the
+end of the document or at --index. The first row is treated as a header when it looks like one
+(see --table-header); a header row is made bold and pinned. Columns whose values are all numbers
+are right-aligned. Use --tab-id to insert into a specific tab (see 'drivectl docs tabs').`,
+	Example: `  drivectl docs insert-table <document-id> --spreadsheet <spreadsheet-id> --sheet Summary --range A1:D8
+  drivectl docs insert-table <document-id> --spreadsheet <spreadsheet-id> --sheet Metrics --index 1 --table-header first-row`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		documentId := args[0]
+		values, err := drive.GetSheetRange(sheetsSvc, docsSpreadsheet, docsSheet, docsRange, drive.ValueRenderOptions{Render: docsRender})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check the spreadsheet ID, the sheet name and the A1 notation of --range.")
+		}
+
+		result, err := drive.InsertDocTable(docsSvc, documentId, values, drive.DocTableOptions{
+			TableOptions: drive.TableOptions{Header: docsTableHeader},
+			TabID:        docsTabId,
+			Index:        docsIndex,
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the document ID is correct, that you can edit it, and that --index falls inside the body.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		ui.PrintSuccess("Inserted a %d×%d table at index %d of %s", result.Rows, result.Columns, result.StartIndex, ui.ID(documentId))
+		return nil
+	},
+}
+
+var docsMergeCmd = &cobra.Command{
+	Use:   "merge <templateDocId>",
+	Short: "Generates a Google Doc per spreadsheet row from a template.",
+	Long: `Copies a template Google Doc once for every row of a sheet, and replaces each {{column}}
+placeholder in the copy with the row's value, where the column is named by the sheet's header row.
+Placeholders are matched case-sensitively in the body, tables, headers, footers and every tab.
+Blank rows are skipped.
+
+Each copy is named from --name, which may contain placeholders too, and filed in --to (by default
+the template's folder). --pdf-dir also exports each copy to a local PDF. --id-column writes the ID
+of each new document back to that column of the sheet, adding it when missing; rows that already
+have an ID there are skipped, so a merge can be re-run after adding rows or after a failure.
+Use --dry-run to list the documents that would be created.`,
+	Example: `  drivectl docs merge <template-id> --data <spreadsheet-id> --sheet Customers
+  drivectl docs merge <template-id> --data <spreadsheet-id> --sheet Customers --name "Proposal - {{Company}}" --to <folder-id>
+  drivectl docs merge <template-id> --data <spreadsheet-id> --sheet Customers --id-column "Doc ID" --pdf-dir ./proposals`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templateId := args[0]
+		opts := drive.MergeOptions{
+			Name:     docsName,
+			Folder:   docsTarget,
+			PDFDir:   docsPDFDir,
+			IDColumn: docsIDColumn,
+			Read:     drive.ValueRenderOptions{Render: docsRender},
+			DryRun:   docsDryRun,
+		}
+		if OutputFormat != "json" {
+			opts.Progress = printMergedDoc
+		}
+
+		result, err := drive.MergeDocs(driveSvc, docsSvc, sheetsSvc, templateId, docsData, docsSheet, opts)
+		if err != nil {
+			if result != nil && OutputFormat != "json" && len(result.Documents) > 0 {
+				fmt.Println(ui.Warn(fmt.Sprintf("%d documents were generated before the error.", len(result.Documents))))
+			}
+			return ui.ErrorWithHint(err, "Ensure the template is a Google Doc, that the sheet has a header row, and that you can write to the target folder.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if len(result.Unmatched) > 0 {
+			fmt.Println(ui.Warn("No column fills: {{" + strings.Join(result.Unmatched, "}}, {{") + "}}"))
+		}
+		if len(result.Skipped) > 0 {
+			fmt.Println(ui.Muted(fmt.Sprintf("Skipped %d rows that already have a document ID.", len(result.Skipped))))
+		}
+		if docsDryRun {
+			fmt.Println(ui.Muted(fmt.Sprintf("%d documents would be created (dry run)", len(result.Documents))))
+		} else {
+			ui.PrintSuccess("Generated %d documents from %s", len(result.Documents), ui.ID(templateId))
+		}
+		return nil
+	},
+}
+
+func printMergedDoc(doc *drive.MergedDoc) {
+	row := ui.Muted(fmt.Sprintf("(row %d)", doc.Row))
+	if doc.DocumentID == "" {
+		fmt.Printf("%s %s %s\n", ui.Pass("+"), doc.Name, row)
+		return
+	}
+	fmt.Printf("%s %s %s %s\n", ui.Pass("+"), doc.Name, ui.ID("("+doc.DocumentID+")"), row)
+	if doc.PDF != "" {
+		fmt.Printf("    %s\n", ui.Muted(doc.PDF))
+	}
+}
+
+var docsReplaceCmd = &cobra.Command{
+	Use:   "replace <documentId>",
+	Short: "Finds and replaces text in a Google Doc.",
+	Long: `Replaces every occurrence of --find with --replace throughout a Google Doc, including tables,
+headers, footers and footnotes. Matching ignores case unless --match-case is given, and covers every
+tab unless --tab-id is given.
+
+With --regex, --find is a regular expression (RE2 syntax) and --replace can refer to its groups as
+$1 or ${name}. Matches are found in each paragraph and replaced in place, keeping their formatting;
+a match cannot span paragraphs.
+
+--vars reads a JSON object of placeholder names to values and replaces each {{name}} with its
+value, in a single request.`,
+	Example: `  drivectl docs replace <document-id> --find "Q3" --replace "Q4"
+  drivectl docs replace <document-id> --find 'v(\d+)\.0' --replace 'v${1}.1' --regex --match-case
+  drivectl docs replace <document-id> --vars vars.json --tab-id <tab-id>`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		documentId := args[0]
+		var replacements []drive.Replacement
+		switch {
+		case docsVars != "" && (cmd.Flags().Changed("find") || docsRegex):
+			return fmt.Errorf("--vars cannot be combined with --find or --regex")
+		case docsVars != "":
+			vars, err := readDocsVars(docsVars)
+			if err != nil {
+				return ui.ErrorWithHint(err, "--vars must be a JSON object of placeholder names to values.")
+			}
+			replacements = vars
+		case docsFind != "":
+			replacements = []drive.Replacement{{Find: docsFind, Replace: docsReplace}}
+		default:
+			return fmt.Errorf("either --find or --vars is required")
+		}
+
+		result, err := drive.ReplaceDocText(docsSvc, documentId, replacements, drive.ReplaceOptions{
+			Regex:     docsRegex,
+			MatchCase: docsMatchCase,
+			TabID:     docsTabId,
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the document ID is correct and that you can edit it.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if len(result.Replacements) > 1 {
+			for _, r := range result.Replacements {
+				fmt.Printf("%s → %s %s\n", r.Find, r.Replace, ui.Muted(fmt.Sprintf("(%d)", r.Occurrences)))
+			}
+		}
+		if result.Total == 0 {
+			fmt.Println(ui.Muted("No matches found."))
+			return nil
+		}
+		ui.PrintSuccess("Replaced %d occurrences in %s", result.Total, ui.ID(documentId))
+		return nil
+	},
+}
+
+var docsOutlineCmd = &cobra.Command{
+	Use:   "outline <documentId>",
+	Short: "Shows the heading hierarchy of a Google Doc.",
+	Long: `Lists the headings of a Google Doc, nested by level, with the index each heading starts at and
+the index its section ends at. A section runs to the next heading of the same or a higher level.
+Use --tab-id to outline a specific tab (see 'drivectl docs tabs').`,
+	Example: `  drivectl docs outline <document-id>
+  drivectl docs outline <document-id> --tab-id <tab-id> -O json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		headings, err := drive.GetDocumentOutline(docsSvc, args[0], docsTabId)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the document ID is correct and is a Google Doc.")
+		}
+
+		if OutputFormat == "json" {
+			if headings == nil {
+				headings = []*drive.Heading{}
+			}
+			b, err := json.MarshalIndent(headings, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if len(headings) == 0 {
+			fmt.Println(ui.Muted("No headings found."))
+			return nil
+		}
+		var printHeadings func(headings []*drive.Heading, depth int)
+		printHeadings = func(headings []*drive.Heading, depth int) {
+			for _, h := range headings {
+				fmt.Printf("%s%s %s %s\n", strings.Repeat("  ", depth), ui.Muted(strings.Repeat("#", h.Level)), h.Text,
+					ui.Muted(fmt.Sprintf("(%d–%d)", h.StartIndex, h.EndIndex)))
+				printHeadings(h.Children, depth+1)
+			}
+		}
+		printHeadings(headings, 0)
+		return nil
+	},
+}
+
+var docsSectionCmd = &cobra.Command{
+	Use:   "section <documentId> <heading>",
+	Short: "Exports one section of a Google Doc as Markdown.",
+	Long: `Exports the section under a heading as Markdown, from the heading down to the next heading of the
+same or a higher level. The heading is matched by its text, ignoring case, or by its heading ID;
+the first match is used. Use 'drivectl docs outline' to list the headings, and --tab-id to read
+a specific tab.`,
+	Example: `  drivectl docs section <document-id> "Rollout Plan"
+  drivectl docs section <document-id> "Rollout Plan" --tab-id <tab-id> -o rollout.md`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		documentId, heading := args[0], args[1]
+		content, err := drive.GetDocumentSection(docsSvc, documentId, docsTabId, heading)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl docs outline "+documentId+"' to list the headings.")
+		}
+
+		if OutputFormat == "json" {
+			res := map[string]interface{}{
+				"documentId": documentId,
+				"heading":    heading,
+				"tabId":      docsTabId,
+				"content":    string(content),
+			}
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if docsOutput != "" {
+			if err := os.WriteFile(docsOutput, content, 0644); err != nil {
+				return ui.ErrorWithHint(fmt.Errorf("failed to write to output file %s: %w", docsOutput, err), "Check file permissions and path.")
+			}
+			ui.PrintSuccess("Saved section to %s", docsOutput)
+			return nil
+		}
+		fmt.Println(string(content))
+		return nil
+	},
+}
+
+var docsDiffCmd = &cobra.Command{
+	Use:   "diff <documentA> <documentB|file.md>",
+	Short: "Shows the differences between two Google Docs, or a Doc and a Markdown file.",
+	Long: `Renders both sides as Markdown and prints a unified diff between them. Each side is a
+Google Doc ID or the path of a local Markdown file ("-" reads stdin). A Markdown file is first
+normalized as if it had been turned into a Google Doc with 'drivectl docs create' and exported
+again, so formatting that does not survive the round trip is not reported as a change.
+
+--word-diff compares the changed lines word by word instead, marking removed text as [-text-]
+and added text as {+text+}, with --context unchanged lines around each change.`,
+	Example: `  drivectl docs diff <draft-doc-id> <published-doc-id>
+  drivectl docs diff <document-id> design.md --word-diff
+  drivectl docs diff <document-id> design.md -O json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := readDocsDiffSide(args[0])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Each side must be a Google Doc ID or the path of a Markdown file.")
+		}
+		to, err := readDocsDiffSide(args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Each side must be a Google Doc ID or the path of a Markdown file.")
+		}
+
+		var out string
+		words := diff.Words(from, to)
+		if docsWordDiff {
+			out = renderWordDiff(words, docsContext, func(s string) string { return "[-" + s + "-]" }, func(s string) string { return "{+" + s + "+}" })
+		} else {
+			out = diff.Unified(args[0], args[1], from, to, docsContext)
+		}
+
+		if OutputFormat == "json" {
+			res := map[string]interface{}{
+				"from":      args[0],
+				"to":        args[1],
+				"identical": from == to,
+				"diff":      out,
+			}
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if from == to {
+			fmt.Println(ui.Muted("Documents are identical."))
+			return nil
+		}
+		if docsWordDiff {
+			fmt.Print(renderWordDiff(words, docsContext,
+				func(s string) string { return ui.Fail("[-" + s + "-]") },
+				func(s string) string { return ui.Pass("{+" + s + "+}") }))
+			return nil
+		}
+		printUnifiedDiff(out)
+		return nil
+	},
+}
+
+// readDocsDiffSide returns the Markdown of one side of a diff: a local
+// Markdown file, normalized through the Docs converter, or a Google Doc.
+func readDocsDiffSide(arg string) (string, error) {
+	var content []byte
+	var err error
+	if arg == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else if _, statErr := os.Stat(arg); statErr == nil {
+		content, err = os.ReadFile(arg)
+	} else {
+		content, err = drive.GetDocumentMarkdown(docsSvc, arg, "")
+		return string(content), err
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", arg, err)
+	}
+	normalized, err := drive.NormalizeMarkdown(string(content))
+	return string(normalized), err
+}
+
+// renderWordDiff renders the lines of a word diff that contain a change,
+// with context unchanged lines around them. Runs of skipped lines are shown
+// as "...".
+func renderWordDiff(edits []diff.Edit, context int, removed, added func(string) string) string {
+	lines := diff.Lines(edits)
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if slices.ContainsFunc(line, func(e diff.Edit) bool { return e.Op != diff.Equal }) {
+			for j := max(0, i-context); j <= min(len(lines)-1, i+context); j++ {
+				keep[j] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	skipped := false
+	for i, line := range lines {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if skipped && b.Len() > 0 {
+			b.WriteString("...\n")
+		}
+		skipped = false
+		for _, e := range line {
+			switch e.Op {
+			case diff.Delete:
+				b.WriteString(removed(e.Text))
+			case diff.Insert:
+				b.WriteString(added(e.Text))
+			default:
+				b.WriteString(e.Text)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// readDocsVars reads a JSON object of placeholder names to values, in the
+// order of their names, as replacements of {{name}}.
+func readDocsVars(path string) ([]drive.Replacement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vars file: %w", err)
+	}
+	rec, err := drive.ParseRecord(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse vars file: %w", err)
+	}
+	names := slices.Sorted(maps.Keys(rec.Values))
+	replacements := make([]drive.Replacement, len(names))
+	for i, name := range names {
+		replacements[i] = drive.Replacement{Find: "{{" + name + "}}", Replace: drive.CellString(rec.Values[name])}
+	}
+	return replacements, nil
+}
+
 func init() {
 	rootCmd.AddCommand(docsCmd)
 	docsCmd.AddCommand(docsTabsCmd)
 	docsCmd.AddCommand(docsCreateCmd)
+	docsCmd.AddCommand(docsInsertTableCmd)
+	docsCmd.AddCommand(docsMergeCmd)
+	docsCmd.AddCommand(docsReplaceCmd)
+	docsCmd.AddCommand(docsOutlineCmd)
+	docsCmd.AddCommand(docsSectionCmd)
+	docsCmd.AddCommand(docsDiffCmd)
+
+	docsInsertTableCmd.Flags().StringVar(&docsSpreadsheet, "spreadsheet", "", "ID of the spreadsheet to read from")
+	_ = docsInsertTableCmd.MarkFlagRequired("spreadsheet")
+	docsInsertTableCmd.Flags().StringVar(&docsSheet, "sheet", "", "Name of the sheet to read from")
+	_ = docsInsertTableCmd.MarkFlagRequired("sheet")
+	docsInsertTableCmd.Flags().StringVar(&docsRange, "range", "", "A1 range to insert (defaults to the whole sheet)")
+	docsInsertTableCmd.Flags().StringVar(&docsTabId, "tab-id", "", "ID of the tab to insert into")
+	docsInsertTableCmd.Flags().Int64Var(&docsIndex, "index", 0, "Body index to insert at (defaults to the end of the document)")
+	docsInsertTableCmd.Flags().StringVar(&docsTableHeader, "table-header", "auto", "Whether the first row is a header (auto, first-row, none)")
+	docsInsertTableCmd.Flags().StringVar(&docsRender, "render", "formatted", "How values are read (formatted, unformatted, formula)")
+
+	docsMergeCmd.Flags().StringVar(&docsData, "data", "", "ID of the spreadsheet holding one row per document")
+	_ = docsMergeCmd.MarkFlagRequired("data")
+	docsMergeCmd.Flags().StringVar(&docsSheet, "sheet", "", "Name of the sheet to read rows from")
+	_ = docsMergeCmd.MarkFlagRequired("sheet")
+	docsMergeCmd.Flags().StringVar(&docsName, "name", "", "Name pattern for each copy, with {{column}} placeholders (defaults to the template name and first column)")
+	docsMergeCmd.Flags().StringVar(&docsTarget, "to", "", "Folder ID to file the copies in (defaults to the template's folder)")
+	docsMergeCmd.Flags().StringVar(&docsPDFDir, "pdf-dir", "", "Local directory to also export each copy to as PDF")
+	docsMergeCmd.Flags().StringVar(&docsIDColumn, "id-column", "", "Sheet column to write each new document ID to")
+	docsMergeCmd.Flags().StringVar(&docsRender, "render", "formatted", "How values are read (formatted, unformatted, formula)")
+	docsMergeCmd.Flags().BoolVar(&docsDryRun, "dry-run", false, "List the documents that would be created without creating them")
+
+	docsReplaceCmd.Flags().StringVar(&docsFind, "find", "", "Text, or with --regex a regular expression, to find")
+	docsReplaceCmd.Flags().StringVar(&docsReplace, "replace", "", "Text to replace each match with")
+	docsReplaceCmd.Flags().BoolVar(&docsRegex, "regex", false, "Treat --find as a regular expression")
+	docsReplaceCmd.Flags().BoolVar(&docsMatchCase, "match-case", false, "Match case")
+	docsReplaceCmd.Flags().StringVar(&docsTabId, "tab-id", "", "ID of the tab to replace in (defaults to every tab)")
+	docsReplaceCmd.Flags().StringVar(&docsVars, "vars", "", "JSON file of placeholder names to values, replacing each {{name}}")
+
+	docsOutlineCmd.Flags().StringVar(&docsTabId, "tab-id", "", "ID of the tab to outline")
+
+	docsSectionCmd.Flags().StringVar(&docsTabId, "tab-id", "", "ID of the tab to read from")
+	docsSectionCmd.Flags().StringVarP(&docsOutput, "output", "o", "", "Path to save the section to")
+
+	docsDiffCmd.Flags().BoolVar(&docsWordDiff, "word-diff", false, "Compare changed lines word by word")
+	docsDiffCmd.Flags().IntVarP(&docsContext, "context", "U", 3, "Number of unchanged lines to show around each change")
 }
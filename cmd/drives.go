@@ -0,0 +1,126 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghchinoy/drivectl/internal/drive"
+	"github.com/ghchinoy/drivectl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var drivesCmd = &cobra.Command{
+	Use:     "drives",
+	GroupID: GroupCore,
+	Short:   "Lists and manages shared drives.",
+	Long: `A set of commands to work with shared drives. To run other commands against a shared drive,
+pass its ID or name with the global --drive flag, or use --all-drives to include every shared drive.`,
+}
+
+var drivesListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists the shared drives you are a member of.",
+	Long:    `Lists every shared drive the signed-in user is a member of.`,
+	Example: `  drivectl drives list`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		drives, err := drive.ListSharedDrives(driveSvc)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure you have network access and are signed in.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(drives, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		fmt.Println(ui.Accent("Shared drives:"))
+		if len(drives) == 0 {
+			fmt.Println(ui.Muted("No shared drives found."))
+			return nil
+		}
+		for _, d := range drives {
+			fmt.Printf("%s %s\n", d.Name, ui.ID("("+d.Id+")"))
+		}
+		return nil
+	},
+}
+
+var drivesDescribeCmd = &cobra.Command{
+	Use:   "describe <driveId|name>",
+	Short: "Shows metadata for a shared drive.",
+	Long:  `Retrieves the metadata, capabilities and restrictions of a shared drive, given its ID or exact name. The output is formatted as a JSON object.`,
+	Example: `  drivectl drives describe <drive-id>
+  drivectl drives describe "Engineering"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := drive.ResolveSharedDrive(driveSvc, args[0])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl drives list' to see the shared drives you can access.")
+		}
+		if d.Capabilities == nil {
+			// Drives resolved by name only carry summary fields.
+			if d, err = drive.GetSharedDrive(driveSvc, d.Id); err != nil {
+				return err
+			}
+		}
+
+		b, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal drive to json: %w", err)
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
+var drivesCreateCmd = &cobra.Command{
+	Use:     "create <name>",
+	Short:   "Creates a shared drive.",
+	Long:    `Creates a new shared drive with the given name. The signed-in user becomes its organizer.`,
+	Example: `  drivectl drives create "Project Phoenix"`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := drive.CreateSharedDrive(driveSvc, args[0])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Your Workspace administrator may restrict who can create shared drives.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(d, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		ui.PrintSuccess("Created shared drive %s %s", d.Name, ui.ID("("+d.Id+")"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(drivesCmd)
+	drivesCmd.AddCommand(drivesListCmd)
+	drivesCmd.AddCommand(drivesDescribeCmd)
+	drivesCmd.AddCommand(drivesCreateCmd)
+}
@@ -0,0 +1,260 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ghchinoy/drivectl/internal/drive"
+	"github.com/ghchinoy/drivectl/internal/ui"
+	"github.com/spf13/cobra"
+	googledrive "google.golang.org/api/drive/v3"
+)
+
+const fileOpHint = "Ensure the IDs are correct and you have edit access. " + reloginHint
+
+var (
+	mkdirParents bool
+	mkdirParent  string
+	mvTarget     string
+	cpTarget     string
+	cpName       string
+	rmYes        bool
+)
+
+// fileOpResult is the outcome of a file management operation on a single item.
+type fileOpResult struct {
+	Input  string            `json:"input"`
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	File   *googledrive.File `json:"file,omitempty"`
+}
+
+// runFileOps applies op to every input, collecting a result per item rather than stopping at the first failure.
+func runFileOps(inputs []string, op func(input string) (*googledrive.File, error)) []*fileOpResult {
+	var results []*fileOpResult
+	for _, input := range inputs {
+		res := &fileOpResult{Input: input, Status: "ok"}
+		file, err := op(input)
+		if err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+		}
+		res.File = file
+		results = append(results, res)
+	}
+	return results
+}
+
+// printFileOpResults renders results and returns an error if any item failed.
+func printFileOpResults(verb string, results []*fileOpResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Status != "ok" {
+			failed++
+		}
+	}
+
+	if OutputFormat == "json" {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, r := range results {
+			switch {
+			case r.Status != "ok":
+				fmt.Fprintf(os.Stderr, "%s %s: %s\n", ui.Fail("✘"), r.Input, r.Error)
+			case r.File != nil:
+				ui.PrintSuccess("%s %s %s", verb, r.File.Name, ui.ID("("+r.File.Id+")"))
+			default:
+				ui.PrintSuccess("%s %s", verb, r.Input)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return ui.ErrorWithHint(fmt.Errorf("%d of %d operations failed", failed, len(results)), fileOpHint)
+	}
+	return nil
+}
+
+// confirm asks the user a yes/no question on stdin, defaulting to no.
+func confirm(prompt string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+var mkdirCmd = &cobra.Command{
+	Use:     "mkdir <name|path>...",
+	GroupID: GroupCore,
+	Short:   "Creates folders in Google Drive.",
+	Long: `Creates one or more folders. With -p, each argument is treated as a slash-separated path
+and any missing intermediate folders are created, reusing folders that already exist.`,
+	Example: `  drivectl mkdir Reports
+  drivectl mkdir -p Projects/2025/Q3
+  drivectl mkdir Drafts Archive --parent <folder-id>`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results := runFileOps(args, func(name string) (*googledrive.File, error) {
+			if mkdirParents {
+				return drive.MkdirAll(driveSvc, name, mkdirParent)
+			}
+			return drive.CreateFolder(driveSvc, name, mkdirParent)
+		})
+		return printFileOpResults("Created folder", results)
+	},
+}
+
+var mvCmd = &cobra.Command{
+	Use:     "mv <fileId>... --to <folderId>",
+	GroupID: GroupCore,
+	Short:   "Moves files to another folder.",
+	Long:    `Moves one or more files or folders into the target folder, replacing their current parents.`,
+	Example: `  drivectl mv <file-id> --to <folder-id>
+  drivectl mv <file-id-1> <file-id-2> --to root`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results := runFileOps(args, func(id string) (*googledrive.File, error) {
+			return drive.MoveFile(driveSvc, id, mvTarget)
+		})
+		return printFileOpResults("Moved", results)
+	},
+}
+
+var cpCmd = &cobra.Command{
+	Use:     "cp <fileId>...",
+	GroupID: GroupCore,
+	Short:   "Copies files on the server.",
+	Long: `Makes server-side copies of one or more files. Folders are copied recursively.
+By default each copy is placed next to the original and keeps the original name.`,
+	Example: `  drivectl cp <file-id>
+  drivectl cp <file-id> --name "Copy of Report" --to <folder-id>
+  drivectl cp <folder-id> --to <backup-folder-id>`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cpName != "" && len(args) > 1 {
+			return fmt.Errorf("--name can only be used when copying a single file")
+		}
+		results := runFileOps(args, func(id string) (*googledrive.File, error) {
+			return drive.CopyFile(driveSvc, id, cpTarget, cpName)
+		})
+		return printFileOpResults("Copied to", results)
+	},
+}
+
+var renameCmd = &cobra.Command{
+	Use:     "rename <fileId> <newName> [<fileId> <newName>]...",
+	GroupID: GroupCore,
+	Short:   "Renames files.",
+	Long:    `Renames one or more files. Arguments are given as pairs of file ID and new name.`,
+	Example: `  drivectl rename <file-id> "Final Report"
+  drivectl rename <id-1> "One" <id-2> "Two"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || len(args)%2 != 0 {
+			return fmt.Errorf("expected pairs of <fileId> <newName>, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ids, names []string
+		for i := 0; i < len(args); i += 2 {
+			ids = append(ids, args[i])
+			names = append(names, args[i+1])
+		}
+		next := 0
+		results := runFileOps(ids, func(id string) (*googledrive.File, error) {
+			name := names[next]
+			next++
+			return drive.RenameFile(driveSvc, id, name)
+		})
+		return printFileOpResults("Renamed to", results)
+	},
+}
+
+var trashCmd = &cobra.Command{
+	Use:     "trash <fileId>...",
+	GroupID: GroupCore,
+	Short:   "Moves files to the trash.",
+	Long:    `Moves one or more files to the Google Drive trash. Use 'untrash' to restore them.`,
+	Example: `  drivectl trash <file-id>`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results := runFileOps(args, func(id string) (*googledrive.File, error) {
+			return drive.TrashFile(driveSvc, id, true)
+		})
+		return printFileOpResults("Trashed", results)
+	},
+}
+
+var untrashCmd = &cobra.Command{
+	Use:     "untrash <fileId>...",
+	GroupID: GroupCore,
+	Short:   "Restores files from the trash.",
+	Long:    `Restores one or more files from the Google Drive trash.`,
+	Example: `  drivectl untrash <file-id>`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results := runFileOps(args, func(id string) (*googledrive.File, error) {
+			return drive.TrashFile(driveSvc, id, false)
+		})
+		return printFileOpResults("Restored", results)
+	},
+}
+
+var rmCmd = &cobra.Command{
+	Use:     "rm <fileId>...",
+	GroupID: GroupCore,
+	Short:   "Permanently deletes files.",
+	Long: `Permanently deletes one or more files, bypassing the trash. This cannot be undone.
+You will be asked to confirm unless --yes is given.`,
+	Example: `  drivectl rm <file-id>
+  drivectl rm <file-id-1> <file-id-2> --yes`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !rmYes && !confirm(fmt.Sprintf("Permanently delete %d file(s)?", len(args))) {
+			return ui.ErrorWithHint(fmt.Errorf("aborted"), "Pass --yes to skip the confirmation prompt, or use 'drivectl trash' instead.")
+		}
+		results := runFileOps(args, func(id string) (*googledrive.File, error) {
+			return nil, drive.DeleteFile(driveSvc, id)
+		})
+		return printFileOpResults("Deleted", results)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mkdirCmd, mvCmd, cpCmd, renameCmd, trashCmd, untrashCmd, rmCmd)
+
+	mkdirCmd.Flags().BoolVarP(&mkdirParents, "parents", "p", false, "Create intermediate folders as needed")
+	mkdirCmd.Flags().StringVar(&mkdirParent, "parent", "", "Parent folder ID to create the folders in")
+
+	mvCmd.Flags().StringVar(&mvTarget, "to", "", "Destination folder ID")
+	_ = mvCmd.MarkFlagRequired("to")
+
+	cpCmd.Flags().StringVar(&cpTarget, "to", "", "Destination folder ID (defaults to the original's folder)")
+	cpCmd.Flags().StringVar(&cpName, "name", "", "Name for the copy (single file only)")
+
+	rmCmd.Flags().BoolVarP(&rmYes, "yes", "y", false, "Do not prompt for confirmation")
+}
@@ -25,9 +25,10 @@ import (
 )
 
 var (
-	outputFile string
-	format     string
-	tabId      string
+	outputFile  string
+	format      string
+	tabId       string
+	suggestions string
 )
 
 var getCmd = &cobra.Command{
@@ -37,15 +38,24 @@ var getCmd = &cobra.Command{
 	Long: `Downloads a file from Google Drive.
 For standard files (PDFs, images, etc.), it downloads the raw content.
 For Google Docs, it can export the entire document to various formats (txt, md, pdf, etc.) using the --format flag.
-It can also extract the plain text content of a single tab from a Google Doc using the --tab-id flag.`,
+It can also extract the plain text content of a single tab from a Google Doc using the --tab-id flag.
+For Google Docs exported with --format md or --tab-id, --suggestions chooses how pending suggestions
+appear: accept shows the text as if they were accepted, reject as if they were rejected, and inline
+marks them with CriticMarkup, as {++inserted++} and {--deleted--} text. Suggestions are not
+attributed, as the Docs API does not expose who made them.
+Drive's export cannot choose how suggestions appear, so with --suggestions the Markdown is built
+from the Docs API by drivectl's own, simpler renderer, the same one --tab-id uses. It keeps
+headings, bullets, bold, italic and links, but drops tables, shows numbered lists as bullets and
+keeps only one style per run of text. Leave out --suggestions to get Drive's full export.`,
 	Example: `  drivectl get <file-id>
   drivectl get <google-doc-id> --format md -o my-doc.md
-  drivectl get <google-doc-id> --tab-id <tab-id>`,
+  drivectl get <google-doc-id> --tab-id <tab-id>
+  drivectl get <google-doc-id> --format md --suggestions inline`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fileId := args[0]
 
-		content, err := drive.GetFile(driveSvc, docsSvc, fileId, format, tabId)
+		content, err := drive.GetFile(driveSvc, docsSvc, fileId, format, tabId, suggestions)
 		if err != nil {
 			return err
 		}
@@ -57,6 +67,7 @@ It can also extract the plain text content of a single tab from a Google Doc usi
 				"content": string(content),
 				"format": format,
 				"tabId": tabId,
+				"suggestions": suggestions,
 			}
 			b, err := json.MarshalIndent(res, "", "  ")
 			if err != nil {
@@ -91,4 +102,5 @@ func init() {
 	getCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Path to save the output file")
 	getCmd.Flags().StringVar(&format, "format", "", "Export format for Google Docs (e.g., pdf, docx, html, txt, md)")
 	getCmd.Flags().StringVar(&tabId, "tab-id", "", "ID of the tab to get content from")
+	getCmd.Flags().StringVar(&suggestions, "suggestions", "", "How to show pending suggestions in Google Docs Markdown (accept, reject, inline); uses a simpler renderer that drops tables and numbered lists")
 }
\ No newline at end of file
@@ -15,22 +15,34 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/ghchinoy/drivectl/internal/diff"
 	"github.com/ghchinoy/drivectl/internal/drive"
 	"github.com/ghchinoy/drivectl/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	revisionFormat     string
+	revisionOutputFile string
+	revisionUnpin      bool
+)
+
 var revisionsCmd = &cobra.Command{
 	Use:     "revisions <file-id>",
 	GroupID: GroupCore,
 	Short:   "Lists revisions for a Google Drive file or Google Doc",
 	Long:    `Retrieves and displays the revision history for a given file ID from the Google Drive API.`,
-	Example: `  drivectl revisions <file-id>`,
-	Args:    cobra.ExactArgs(1),
+	Example: `  drivectl revisions <file-id>
+  drivectl revisions get <file-id> <revision-id> --format md
+  drivectl revisions diff <file-id> <revision-a> <revision-b>`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fileId := args[0]
 		revisions, err := drive.ListRevisions(driveSvc, fileId)
@@ -75,6 +87,192 @@ var revisionsCmd = &cobra.Command{
 	},
 }
 
+var revisionsGetCmd = &cobra.Command{
+	Use:   "get <file-id> <revision-id>",
+	Short: "Downloads or exports a specific revision.",
+	Long: `Downloads the content of a specific revision of a file.
+Revisions of Google Docs, Sheets and Slides are exported using the --format flag, as with 'get'.`,
+	Example: `  drivectl revisions get <file-id> <revision-id> -o old.pdf
+  drivectl revisions get <google-doc-id> <revision-id> --format md`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fileId, revisionId := args[0], args[1]
+		content, err := drive.GetRevisionContent(driveSvc, client, fileId, revisionId, revisionFormat)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl revisions <file-id>' to list valid revision IDs.")
+		}
+
+		if OutputFormat == "json" {
+			res := map[string]interface{}{
+				"fileId":     fileId,
+				"revisionId": revisionId,
+				"format":     revisionFormat,
+				"content":    string(content),
+			}
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if revisionOutputFile != "" {
+			if err := os.WriteFile(revisionOutputFile, content, 0644); err != nil {
+				return ui.ErrorWithHint(fmt.Errorf("failed to write to output file %s: %w", revisionOutputFile, err), "Check file permissions and path.")
+			}
+			ui.PrintSuccess("Saved revision %s to %s", revisionId, revisionOutputFile)
+			return nil
+		}
+		fmt.Println(string(content))
+		return nil
+	},
+}
+
+var revisionsDiffCmd = &cobra.Command{
+	Use:   "diff <file-id> <revision-a> <revision-b>",
+	Short: "Shows a unified diff between two revisions.",
+	Long: `Exports two revisions of a file as text and prints a unified diff between them.
+Google Docs are exported as plain text by default; use --format md to compare Markdown.
+Binary files, such as PDFs and images, cannot be compared.`,
+	Example: `  drivectl revisions diff <file-id> <revision-a> <revision-b>
+  drivectl revisions diff <google-doc-id> <revision-a> <revision-b> --format md`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fileId, revA, revB := args[0], args[1], args[2]
+		contentA, err := drive.GetRevisionContent(driveSvc, client, fileId, revA, revisionFormat)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl revisions <file-id>' to list valid revision IDs.")
+		}
+		contentB, err := drive.GetRevisionContent(driveSvc, client, fileId, revB, revisionFormat)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl revisions <file-id>' to list valid revision IDs.")
+		}
+		if !isText(contentA) || !isText(contentB) {
+			return ui.ErrorWithHint(fmt.Errorf("revisions of %s are not text and cannot be compared", fileId),
+				"Google Docs, Sheets and Slides can be compared as text with --format txt, md or csv. Download other files with 'drivectl revisions get'.")
+		}
+
+		unified := diff.Unified("revision "+revA, "revision "+revB, string(contentA), string(contentB), 3)
+
+		if OutputFormat == "json" {
+			res := map[string]interface{}{
+				"fileId":    fileId,
+				"from":      revA,
+				"to":        revB,
+				"identical": unified == "",
+				"diff":      unified,
+			}
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if unified == "" {
+			fmt.Println(ui.Muted("Revisions are identical."))
+			return nil
+		}
+		printUnifiedDiff(unified)
+		return nil
+	},
+}
+
+// isText reports whether content is UTF-8 text without NUL bytes, as binary
+// files such as PDFs and images are not.
+func isText(content []byte) bool {
+	return utf8.Valid(content) && bytes.IndexByte(content, 0) < 0
+}
+
+// printUnifiedDiff prints a unified diff with its additions, removals and
+// hunk headers coloured.
+func printUnifiedDiff(unified string) {
+	for _, line := range diff.SplitLines(unified) {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Println(ui.Accent(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(ui.Muted(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(ui.Pass(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(ui.Fail(line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+var revisionsKeepCmd = &cobra.Command{
+	Use:   "keep <file-id> <revision-id>",
+	Short: "Pins a revision so it is kept forever.",
+	Long: `Marks a revision of a binary file to be kept forever, so Google Drive does not purge it
+automatically. Use --unpin to clear the flag.`,
+	Example: `  drivectl revisions keep <file-id> <revision-id>
+  drivectl revisions keep <file-id> <revision-id> --unpin`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rev, err := drive.KeepRevision(driveSvc, args[0], args[1], !revisionUnpin)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Only revisions of binary files can be pinned, and you need edit access.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(rev, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if rev.KeepForever {
+			ui.PrintSuccess("Revision %s will be kept forever.", rev.Id)
+		} else {
+			ui.PrintSuccess("Revision %s is no longer pinned.", rev.Id)
+		}
+		return nil
+	},
+}
+
+var revisionsRestoreCmd = &cobra.Command{
+	Use:   "restore <file-id> <revision-id>",
+	Short: "Restores an old revision of a binary file.",
+	Long: `Downloads an old revision of a binary file and uploads it as the file's current content,
+creating a new head revision. Google Docs, Sheets and Slides are not supported.`,
+	Example: `  drivectl revisions restore <file-id> <revision-id>`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := drive.RestoreRevision(driveSvc, client, args[0], args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the revision ID is correct and you have edit access.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(file, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		ui.PrintSuccess("Restored %s to revision %s (new revision %s)", file.Name, args[1], file.HeadRevisionId)
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(revisionsCmd)
+	revisionsCmd.AddCommand(revisionsGetCmd)
+	revisionsCmd.AddCommand(revisionsDiffCmd)
+	revisionsCmd.AddCommand(revisionsKeepCmd)
+	revisionsCmd.AddCommand(revisionsRestoreCmd)
+
+	revisionsGetCmd.Flags().StringVar(&revisionFormat, "format", "", "Export format for Google Docs, Sheets and Slides (e.g., md, txt, pdf, csv)")
+	revisionsGetCmd.Flags().StringVarP(&revisionOutputFile, "output", "o", "", "Path to save the revision content")
+	revisionsDiffCmd.Flags().StringVar(&revisionFormat, "format", "", "Export format used for comparison (e.g., md, txt, csv)")
+	revisionsKeepCmd.Flags().BoolVar(&revisionUnpin, "unpin", false, "Stop keeping the revision forever")
 }
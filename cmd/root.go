@@ -23,6 +23,10 @@ var (
 	OutputFormat string
 	// noBrowserAuth is a flag to disable opening the browser for authentication.
 	noBrowserAuth bool
+	// sharedDrive is the ID or name of the shared drive to operate on.
+	sharedDrive string
+	// allDrives includes items from all shared drives in listings.
+	allDrives bool
 	// client is the HTTP client used for all API calls.
 	client *http.Client
 	// driveSvc is the Google Drive service client.
@@ -76,6 +80,16 @@ Google Docs, including exporting to multiple formats and accessing individual ta
 		if err != nil {
 			return fmt.Errorf("could not create sheets service: %w", err)
 		}
+
+		scope := drive.Scope{AllDrives: allDrives}
+		if sharedDrive != "" {
+			d, err := drive.ResolveSharedDrive(driveSvc, sharedDrive)
+			if err != nil {
+				return ui.ErrorWithHint(err, "Run 'drivectl drives list' to see the shared drives you can access.")
+			}
+			scope.DriveID = d.Id
+		}
+		drive.SetScope(scope)
 		return nil
 	},
 }
@@ -104,6 +118,8 @@ func init() {
 	rootCmd.PersistentFlags().String("secret-file", "", "path to your client secrets file")
 	rootCmd.PersistentFlags().StringVarP(&OutputFormat, "output-format", "O", "", "output format (e.g. json)")
 	rootCmd.PersistentFlags().BoolVar(&noBrowserAuth, "no-browser-auth", false, "do not open a browser for authentication")
+	rootCmd.PersistentFlags().StringVar(&sharedDrive, "drive", "", "ID or name of the shared drive to operate on")
+	rootCmd.PersistentFlags().BoolVar(&allDrives, "all-drives", false, "include items from all shared drives")
 	rootCmd.PersistentFlags().Bool("mcp", false, "enable MCP server mode over stdio")
 	rootCmd.PersistentFlags().String("mcp-http", "", "enable MCP server mode over HTTP at the given address")
 	_ = viper.BindPFlag("secret-file", rootCmd.PersistentFlags().Lookup("secret-file"))
@@ -0,0 +1,502 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ghchinoy/drivectl/internal/drive"
+	"github.com/ghchinoy/drivectl/internal/ui"
+	"github.com/spf13/cobra"
+	googledrive "google.golang.org/api/drive/v3"
+)
+
+var (
+	shareUser      string
+	shareGroup     string
+	shareDomain    string
+	shareAnyone    bool
+	shareLinkOnly  bool
+	shareRole      string
+	shareExpires   string
+	shareNotify    bool
+	shareMessage   string
+	aclRecursive   bool
+	aclDryRun      bool
+	permUpdateRole string
+)
+
+// aclResult is the outcome of an access control change on a single file.
+type aclResult struct {
+	FileID  string                    `json:"fileId"`
+	Path    string                    `json:"path"`
+	Status  string                    `json:"status"`
+	Error   string                    `json:"error,omitempty"`
+	Changes []*drive.PermissionChange `json:"changes"`
+}
+
+// aclEdit describes an access control change: plan computes the desired ACL
+// from the current one, or rejects the change, and apply performs it against
+// the API.
+type aclEdit struct {
+	plan  func(acl []*googledrive.Permission) ([]*googledrive.Permission, error)
+	apply func(fileId string, acl []*googledrive.Permission) error
+}
+
+// aclDelta is the difference between a folder's ACL and the ACL planned for
+// it, which the files inside the folder inherit.
+type aclDelta struct {
+	set     []*googledrive.Permission
+	removed []*googledrive.Permission
+}
+
+func newACLDelta(before, after []*googledrive.Permission) aclDelta {
+	var d aclDelta
+	for _, a := range after {
+		b := drive.FindPermission(before, a)
+		if b == nil || len(drive.DiffPermissions([]*googledrive.Permission{b}, []*googledrive.Permission{a})) > 0 {
+			d.set = append(d.set, a)
+		}
+	}
+	for _, b := range before {
+		if drive.FindPermission(after, b) == nil {
+			d.removed = append(d.removed, b)
+		}
+	}
+	return d
+}
+
+// inherit returns acl as it would be once the delta reached it from a parent folder.
+func (d aclDelta) inherit(acl []*googledrive.Permission) []*googledrive.Permission {
+	var out []*googledrive.Permission
+	for _, p := range acl {
+		if drive.FindPermission(d.removed, p) == nil && drive.FindPermission(d.set, p) == nil {
+			out = append(out, p)
+		}
+	}
+	return append(out, d.set...)
+}
+
+// runACLEdit applies edit to fileId, and to every descendant when recursive is set.
+// With dryRun, changes are computed and reported but not applied.
+func runACLEdit(fileId string, recursive bool, dryRun bool, edit aclEdit) ([]*aclResult, error) {
+	var results []*aclResult
+	// In a real run, the files inside a folder inherit the changes made to
+	// it before they are reached. A dry run changes nothing, so it passes the
+	// changes planned for each folder on to its contents instead.
+	inherited := make(map[string]aclDelta)
+	process := func(file *googledrive.File, path string) error {
+		res := &aclResult{FileID: file.Id, Path: path}
+		results = append(results, res)
+
+		acl, err := drive.ListPermissions(driveSvc, file.Id)
+		if err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return nil
+		}
+		current := acl
+		if dryRun {
+			for _, parent := range file.Parents {
+				if d, ok := inherited[parent]; ok {
+					current = d.inherit(current)
+				}
+			}
+		}
+		after, err := edit.plan(current)
+		if err != nil {
+			res.Status = "error"
+			res.Error = err.Error()
+			return nil
+		}
+		if dryRun && file.MimeType == drive.FolderMimeType {
+			inherited[file.Id] = newACLDelta(acl, after)
+		}
+		res.Changes = drive.DiffPermissions(current, after)
+		switch {
+		case len(res.Changes) == 0:
+			res.Status = "unchanged"
+		case dryRun:
+			res.Status = "planned"
+		default:
+			res.Status = "applied"
+			if err := edit.apply(file.Id, acl); err != nil {
+				res.Status = "error"
+				res.Error = err.Error()
+			}
+		}
+		return nil
+	}
+
+	if recursive {
+		if err := drive.WalkFolder(driveSvc, fileId, process); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	file, err := drive.DescribeFile(driveSvc, fileId)
+	if err != nil {
+		return nil, err
+	}
+	_ = process(file, file.Name)
+	return results, nil
+}
+
+// printACLResults renders results and returns an error if any file failed.
+func printACLResults(results []*aclResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Status == "error" {
+			failed++
+		}
+	}
+
+	if OutputFormat == "json" {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, r := range results {
+			fmt.Printf("%s %s %s\n", r.Path, ui.ID("("+r.FileID+")"), ui.Muted(r.Status))
+			if r.Error != "" {
+				fmt.Printf("  %s %s\n", ui.Fail("✘"), r.Error)
+			}
+			for _, c := range r.Changes {
+				switch c.Op {
+				case "add":
+					fmt.Printf("  %s %s %s %s%s\n", ui.Pass("+"), c.Role, c.Type, c.Target, ui.Muted(aclChangeDetail(c)))
+				case "remove":
+					fmt.Printf("  %s %s %s %s\n", ui.Fail("-"), c.PreviousRole, c.Type, c.Target)
+				case "change":
+					role := c.Role
+					if c.PreviousRole != c.Role {
+						role = c.PreviousRole + " -> " + c.Role
+					}
+					fmt.Printf("  %s %s %s %s%s\n", ui.Warn("~"), role, c.Type, c.Target, ui.Muted(aclChangeDetail(c)))
+				}
+			}
+		}
+	}
+
+	if failed > 0 {
+		return ui.ErrorWithHint(fmt.Errorf("%d of %d files failed", failed, len(results)), "Ensure you own or can share these files. "+reloginHint)
+	}
+	return nil
+}
+
+// aclChangeDetail describes the expiration and discoverability of an added or
+// changed permission, or returns "" when neither is set.
+func aclChangeDetail(c *drive.PermissionChange) string {
+	var details []string
+	if c.ExpirationTime != "" {
+		details = append(details, "expires "+c.ExpirationTime)
+	}
+	if c.AllowFileDiscovery != nil {
+		if *c.AllowFileDiscovery {
+			details = append(details, "discoverable")
+		} else {
+			details = append(details, "link only")
+		}
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
+
+// parseExpiration accepts an RFC 3339 timestamp, a Go duration such as 72h, or a number of days such as 7d.
+func parseExpiration(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().Add(time.Duration(n) * 24 * time.Hour).UTC().Format(time.RFC3339), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid expiration %q: use an RFC 3339 time, a duration like 72h, or days like 7d", s)
+	}
+	return time.Now().Add(d).UTC().Format(time.RFC3339), nil
+}
+
+// permissionFromFlags builds the permission requested by the share flags.
+func permissionFromFlags() (*googledrive.Permission, error) {
+	perm := &googledrive.Permission{Role: shareRole}
+	set := 0
+	if shareUser != "" {
+		perm.Type, perm.EmailAddress = "user", shareUser
+		set++
+	}
+	if shareGroup != "" {
+		perm.Type, perm.EmailAddress = "group", shareGroup
+		set++
+	}
+	if shareDomain != "" {
+		perm.Type, perm.Domain = "domain", shareDomain
+		set++
+	}
+	if shareAnyone {
+		perm.Type = "anyone"
+		set++
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("exactly one of --user, --group, --domain or --anyone is required")
+	}
+	if perm.Type == "domain" || perm.Type == "anyone" {
+		perm.AllowFileDiscovery = !shareLinkOnly
+	}
+
+	expires, err := parseExpiration(shareExpires)
+	if err != nil {
+		return nil, err
+	}
+	if expires != "" && perm.Type != "user" && perm.Type != "group" {
+		return nil, fmt.Errorf("--expires only applies to --user and --group: Drive does not allow %s permissions to expire", perm.Type)
+	}
+	perm.ExpirationTime = expires
+	return perm, nil
+}
+
+var shareCmd = &cobra.Command{
+	Use:     "share <fileId>",
+	GroupID: GroupCore,
+	Short:   "Shares a file with a user, group, domain or anyone.",
+	Long: `Grants access to a file or folder. If the grantee already has access, their role and expiration
+are updated, and for a domain, whether its members can find the file in search. Anyone who can
+find the file and anyone with the link are separate grantees: switching between them adds a
+permission rather than changing the existing one. Only user and group permissions can expire.
+Use --recursive to apply the change to every file inside a folder, and --dry-run to preview
+the resulting changes to each file's access control list without applying them.`,
+	Example: `  drivectl share <file-id> --user a@example.com --role writer
+  drivectl share <file-id> --domain example.com --role reader --link
+  drivectl share <file-id> --anyone --link
+  drivectl share <folder-id> --group team@example.com --recursive --dry-run
+  drivectl share <file-id> --user a@example.com --expires 7d --notify=false`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		perm, err := permissionFromFlags()
+		if err != nil {
+			return err
+		}
+
+		results, err := runACLEdit(args[0], aclRecursive, aclDryRun, aclEdit{
+			plan: func(acl []*googledrive.Permission) ([]*googledrive.Permission, error) {
+				var after []*googledrive.Permission
+				for _, p := range acl {
+					if drive.FindPermission([]*googledrive.Permission{perm}, p) == nil {
+						after = append(after, p)
+					}
+				}
+				return append(after, perm), nil
+			},
+			apply: func(fileId string, acl []*googledrive.Permission) error {
+				if existing := drive.FindPermission(acl, perm); existing != nil {
+					update := drive.PermissionUpdate{Role: perm.Role, ExpirationTime: perm.ExpirationTime}
+					if perm.Type == "domain" {
+						update.AllowFileDiscovery = &perm.AllowFileDiscovery
+					}
+					_, err := drive.UpdatePermission(driveSvc, fileId, existing.Id, update)
+					return err
+				}
+				_, err := drive.CreatePermission(driveSvc, fileId, perm, drive.ShareOptions{
+					Notify:  shareNotify,
+					Message: shareMessage,
+				})
+				return err
+			},
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the file ID is correct and you have permission to access it.")
+		}
+		return printACLResults(results)
+	},
+}
+
+var permissionsCmd = &cobra.Command{
+	Use:     "permissions",
+	GroupID: GroupCore,
+	Short:   "Lists and manages permissions on a file.",
+	Long:    `A set of commands to inspect and change who has access to a file or folder.`,
+}
+
+var permissionsListCmd = &cobra.Command{
+	Use:     "list <fileId>",
+	Short:   "Lists the permissions on a file.",
+	Long:    `Lists every permission on a file, including its ID, which is needed to update or remove it.`,
+	Example: `  drivectl permissions list <file-id>`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		perms, err := drive.ListPermissions(driveSvc, args[0])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the file ID is correct and you have permission to access it.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(perms, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		fmt.Println(ui.Accent(fmt.Sprintf("Permissions for %s:", args[0])))
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		for _, p := range perms {
+			expires := ""
+			if p.ExpirationTime != "" {
+				expires = "expires " + p.ExpirationTime
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ui.ID(p.Id), p.Role, p.Type, drive.PermissionTarget(p), ui.Muted(expires))
+		}
+		return w.Flush()
+	},
+}
+
+var permissionsUpdateCmd = &cobra.Command{
+	Use:   "update <fileId> <permissionId>",
+	Short: "Changes the role or expiration of a permission.",
+	Long: `Updates an existing permission, identified by the ID shown in 'permissions list'.
+With --recursive, the same permission ID is updated on every file inside a folder where it is present.`,
+	Example: `  drivectl permissions update <file-id> <permission-id> --role commenter
+  drivectl permissions update <file-id> <permission-id> --expires 2025-12-31T00:00:00Z`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		permissionId := args[1]
+		expires, err := parseExpiration(shareExpires)
+		if err != nil {
+			return err
+		}
+		if permUpdateRole == "" && expires == "" {
+			return fmt.Errorf("nothing to update: specify --role and/or --expires")
+		}
+
+		results, err := runACLEdit(args[0], aclRecursive, aclDryRun, aclEdit{
+			plan: func(acl []*googledrive.Permission) ([]*googledrive.Permission, error) {
+				var after []*googledrive.Permission
+				for _, p := range acl {
+					if p.Id == permissionId {
+						if expires != "" && p.Type != "user" && p.Type != "group" {
+							return nil, fmt.Errorf("permission %s is for %s, which cannot expire: only user and group permissions can", permissionId, drive.PermissionTarget(p))
+						}
+						updated := *p
+						if permUpdateRole != "" {
+							updated.Role = permUpdateRole
+						}
+						if expires != "" {
+							updated.ExpirationTime = expires
+						}
+						p = &updated
+					}
+					after = append(after, p)
+				}
+				return after, nil
+			},
+			apply: func(fileId string, acl []*googledrive.Permission) error {
+				_, err := drive.UpdatePermission(driveSvc, fileId, permissionId, drive.PermissionUpdate{
+					Role:           permUpdateRole,
+					ExpirationTime: expires,
+				})
+				return err
+			},
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the file ID is correct and you have permission to access it.")
+		}
+		return printACLResults(results)
+	},
+}
+
+var permissionsRemoveCmd = &cobra.Command{
+	Use:   "remove <fileId> <permissionId>...",
+	Short: "Revokes permissions from a file.",
+	Long: `Removes one or more permissions, identified by the IDs shown in 'permissions list'.
+With --recursive, the permissions are removed from every file inside a folder where they are present.`,
+	Example: `  drivectl permissions remove <file-id> <permission-id>
+  drivectl permissions remove <folder-id> anyoneWithLink --recursive --dry-run`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remove := make(map[string]bool)
+		for _, id := range args[1:] {
+			remove[id] = true
+		}
+
+		results, err := runACLEdit(args[0], aclRecursive, aclDryRun, aclEdit{
+			plan: func(acl []*googledrive.Permission) ([]*googledrive.Permission, error) {
+				var after []*googledrive.Permission
+				for _, p := range acl {
+					if !remove[p.Id] {
+						after = append(after, p)
+					}
+				}
+				return after, nil
+			},
+			apply: func(fileId string, acl []*googledrive.Permission) error {
+				for _, p := range acl {
+					if remove[p.Id] {
+						if err := drive.DeletePermission(driveSvc, fileId, p.Id); err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Ensure the file ID is correct and you have permission to access it.")
+		}
+		return printACLResults(results)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(permissionsCmd)
+	permissionsCmd.AddCommand(permissionsListCmd)
+	permissionsCmd.AddCommand(permissionsUpdateCmd)
+	permissionsCmd.AddCommand(permissionsRemoveCmd)
+
+	shareCmd.Flags().StringVar(&shareUser, "user", "", "Email address of the user to share with")
+	shareCmd.Flags().StringVar(&shareGroup, "group", "", "Email address of the group to share with")
+	shareCmd.Flags().StringVar(&shareDomain, "domain", "", "Domain to share with")
+	shareCmd.Flags().BoolVar(&shareAnyone, "anyone", false, "Share with anyone")
+	shareCmd.Flags().BoolVar(&shareLinkOnly, "link", false, "For --domain or --anyone, only grant access to people with the link")
+	shareCmd.Flags().StringVar(&shareRole, "role", "reader", "Role to grant (reader, commenter, writer, fileOrganizer, organizer)")
+	shareCmd.Flags().StringVar(&shareExpires, "expires", "", "For --user or --group, expiration as an RFC 3339 time, a duration (72h), or days (7d)")
+	shareCmd.Flags().BoolVar(&shareNotify, "notify", true, "Send a notification email to users and groups")
+	shareCmd.Flags().StringVar(&shareMessage, "message", "", "Custom message for the notification email")
+
+	permissionsUpdateCmd.Flags().StringVar(&permUpdateRole, "role", "", "New role for the permission")
+	permissionsUpdateCmd.Flags().StringVar(&shareExpires, "expires", "", "New expiration of a user or group permission as an RFC 3339 time, a duration (72h), or days (7d)")
+
+	for _, c := range []*cobra.Command{shareCmd, permissionsUpdateCmd, permissionsRemoveCmd} {
+		c.Flags().BoolVarP(&aclRecursive, "recursive", "r", false, "Apply to every file inside a folder")
+		c.Flags().BoolVar(&aclDryRun, "dry-run", false, "Show the resulting permission changes without applying them")
+	}
+}
@@ -1,23 +1,153 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/ghchinoy/drivectl/internal/a1"
+	"github.com/ghchinoy/drivectl/internal/diff"
 	"github.com/ghchinoy/drivectl/internal/drive"
+	sheetquery "github.com/ghchinoy/drivectl/internal/query"
 	"github.com/ghchinoy/drivectl/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	sheetName        string
-	sheetRange       string
-	sheetsOutputFile string
+	sheetName            string
+	sheetRange           string
+	sheetsOutputFile     string
+	sheetsRender         string
+	sheetsDateTimeRender string
+	sheetsTableHeader    string
+
+	sheetsRecords       bool
+	sheetsHeaderRow     int
+	sheetsInferTypes    bool
+	sheetsKeepBlankRows bool
+
+	sheetsYes     bool
+	sheetsTabName string
+
+	sheetsKey             string
+	sheetsWithSheet       string
+	sheetsWithSpreadsheet string
+	sheetsWithFile        string
+	sheetsWithRevision    string
+
+	sheetsDeleteMissing bool
+	sheetsDryRun        bool
+
+	sheetsAnchor        string
+	sheetsClear         bool
+	sheetsCreate        bool
+	sheetsInput         string
+	sheetsInputFormat   string
+	sheetsChunkRows     int
+	sheetsBatchSize     int
+	sheetsFlushInterval time.Duration
 )
 
+// sheetsRenderOptions returns the value render options selected by the flags.
+func sheetsRenderOptions() drive.ValueRenderOptions {
+	return drive.ValueRenderOptions{
+		Render:         sheetsRender,
+		DateTimeRender: sheetsDateTimeRender,
+	}
+}
+
+// writeSheetsOutput prints encoded sheet data, or saves it when --output is set.
+func writeSheetsOutput(data string) error {
+	if sheetsOutputFile != "" {
+		err := os.WriteFile(sheetsOutputFile, []byte(data), 0644)
+		if err != nil {
+			return ui.ErrorWithHint(fmt.Errorf("failed to write to output file %s: %w", sheetsOutputFile, err), "Check file path permissions.")
+		}
+		ui.PrintSuccess("Saved sheet to %s", sheetsOutputFile)
+		return nil
+	}
+	fmt.Print(data)
+	return nil
+}
+
+// encodeSheetValues encodes values in the --format chosen for get and get-range.
+// Markdown and HTML tables detect the header row unless --table-header says otherwise.
+func encodeSheetValues(values [][]interface{}, format string) (string, error) {
+	opts := drive.TableOptions{Header: sheetsTableHeader}
+	switch format {
+	case "md", "markdown":
+		return drive.MarkdownTable(values, opts)
+	case "html":
+		return drive.HTMLTable(values, opts)
+	}
+	return drive.EncodeValues(values, format)
+}
+
+// addSheetsReadFlags registers the output and render flags shared by commands that read values.
+// The --format flag is not bound to a variable because its default differs between commands.
+func addSheetsReadFlags(cmd *cobra.Command, defaultFormat string) {
+	cmd.Flags().String("format", defaultFormat, "Output format (csv, tsv, json, ndjson, md, html)")
+	cmd.Flags().StringVar(&sheetsTableHeader, "table-header", "auto", "With md or html, whether the first row is a header (auto, first-row, none)")
+	cmd.Flags().StringVar(&sheetsRender, "render", "formatted", "How values are rendered (formatted, unformatted, formula)")
+	cmd.Flags().StringVar(&sheetsDateTimeRender, "date-time-render", "serial", "How unformatted dates are rendered (serial, formatted)")
+	cmd.Flags().StringVarP(&sheetsOutputFile, "output", "o", "", "Path to save the output file")
+	cmd.Flags().BoolVar(&sheetsRecords, "records", false, "Output rows as objects keyed by the header row (json or ndjson)")
+	cmd.Flags().IntVar(&sheetsHeaderRow, "header-row", 1, "Row within the data that holds the keys for --records")
+	cmd.Flags().BoolVar(&sheetsInferTypes, "infer-types", true, "With --records, convert numbers, booleans and dates, and empty cells to null")
+	cmd.Flags().BoolVar(&sheetsKeepBlankRows, "keep-blank-rows", false, "With --records, keep rows that have no values")
+}
+
+// recordOptions returns the record options selected by the flags.
+func recordOptions() drive.RecordOptions {
+	return drive.RecordOptions{
+		HeaderRow:     sheetsHeaderRow,
+		InferTypes:    sheetsInferTypes,
+		KeepBlankRows: sheetsKeepBlankRows,
+	}
+}
+
+// writeSheetsRecords converts values into records and writes them as a JSON
+// array, or as NDJSON when --format ndjson is given.
+func writeSheetsRecords(cmd *cobra.Command, values [][]interface{}, opts drive.RecordOptions) error {
+	records, err := drive.ValuesToRecords(values, opts)
+	if err != nil {
+		return err
+	}
+
+	format := "json"
+	if cmd.Flags().Changed("format") {
+		format, _ = cmd.Flags().GetString("format")
+	}
+	var out strings.Builder
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		out.Write(b)
+		out.WriteString("\n")
+	case "ndjson":
+		for _, rec := range records {
+			b, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			out.Write(b)
+			out.WriteString("\n")
+		}
+	default:
+		return fmt.Errorf("invalid format for --records: %s. Valid formats are: json, ndjson", format)
+	}
+	return writeSheetsOutput(out.String())
+}
+
 var sheetsCmd = &cobra.Command{
 	Use:     "sheets",
 	GroupID: GroupIntegration,
@@ -28,7 +158,7 @@ var sheetsCmd = &cobra.Command{
 var sheetsListCmd = &cobra.Command{
 	Use:     "list [spreadsheetId]",
 	Short:   "Lists the sheets in a spreadsheet.",
-	Long:    `Lists all the individual sheets (tabs) within a given spreadsheet.`,
+	Long:    `Lists all the individual sheets (tabs) within a given spreadsheet, with each sheet's ID, grid size, frozen rows and columns, and whether it is hidden.`,
 	Example: `  drivectl sheets list <spreadsheet-id>`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -48,31 +178,68 @@ var sheetsListCmd = &cobra.Command{
 		}
 
 		fmt.Println(ui.Accent("Sheets:"))
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
 		for _, sheet := range sheets {
-			fmt.Println(sheet)
+			details := fmt.Sprintf("%d×%d", sheet.Rows, sheet.Columns)
+			if sheet.FrozenRows > 0 || sheet.FrozenColumns > 0 {
+				details += fmt.Sprintf(", frozen %d×%d", sheet.FrozenRows, sheet.FrozenColumns)
+			}
+			if sheet.Hidden {
+				details += ", hidden"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", sheet.Title, ui.ID(fmt.Sprintf("(%d)", sheet.SheetID)), ui.Muted(details))
 		}
+		w.Flush()
 		return nil
 	},
 }
 
 var sheetsGetCmd = &cobra.Command{
-	Use:     "get [spreadsheetId]",
-	Short:   "Gets a sheet as CSV.",
-	Long:    `Retrieves the entire content of a specified sheet and outputs it as CSV. An optional output file can be specified.`,
-	Example: `  drivectl sheets get <spreadsheet-id> --sheet Sheet1`,
-	Args:    cobra.ExactArgs(1),
+	Use:   "get [spreadsheetId]",
+	Short: "Gets a sheet as CSV.",
+	Long: `Retrieves the entire content of a specified sheet and outputs it as CSV. Use --format to
+output TSV, a JSON array of rows, NDJSON (one JSON array per row), or a Markdown or HTML table
+instead. An optional output file can be specified.
+
+Markdown and HTML tables treat the first row as a header when it looks like one (text only, no
+repeated values); --table-header first-row or none overrides this. Columns whose values are all
+numbers are right-aligned, and cell text is escaped. To insert a range into a Google Doc as a
+native table, use 'drivectl docs insert-table'.
+
+--render chooses between formatted values as shown in the UI, unformatted values, or formulas.
+With unformatted values, --date-time-render chooses between serial numbers and formatted dates.
+
+--records outputs an array of objects keyed by the header row instead, converting text that looks
+like a number, boolean or date, and dropping blank rows.`,
+	Example: `  drivectl sheets get <spreadsheet-id> --sheet Sheet1
+  drivectl sheets get <spreadsheet-id> --sheet Sheet1 --format tsv -o sheet.tsv
+  drivectl sheets get <spreadsheet-id> --sheet Sheet1 --format md
+  drivectl sheets get <spreadsheet-id> --sheet Sheet1 --format html --table-header first-row -o table.html
+  drivectl sheets get <spreadsheet-id> --sheet Sheet1 --render formula
+  drivectl sheets get <spreadsheet-id> --sheet Sheet1 --records
+  drivectl sheets get <spreadsheet-id> --sheet Sheet1 --records --header-row 3 --format ndjson`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		spreadsheetId := args[0]
-		csv, err := drive.GetSheetAsCSV(sheetsSvc, spreadsheetId, sheetName)
+		values, err := drive.GetSheetRange(sheetsSvc, spreadsheetId, sheetName, "", sheetsRenderOptions())
 		if err != nil {
 			return ui.ErrorWithHint(err, "Check if the sheet name exists in the given spreadsheet ID.")
 		}
+		if sheetsRecords {
+			return writeSheetsRecords(cmd, values, recordOptions())
+		}
+		format, _ := cmd.Flags().GetString("format")
+		data, err := encodeSheetValues(values, format)
+		if err != nil {
+			return err
+		}
 
-		if OutputFormat == "json" {
+		// With -O json and no explicit --format, keep wrapping the CSV in an object.
+		if OutputFormat == "json" && !cmd.Flags().Changed("format") {
 			res := map[string]interface{}{
 				"spreadsheetId": spreadsheetId,
-				"sheetName": sheetName,
-				"csv": csv,
+				"sheetName":     sheetName,
+				"csv":           data,
 			}
 			b, err := json.MarshalIndent(res, "", "  ")
 			if err != nil {
@@ -82,33 +249,44 @@ var sheetsGetCmd = &cobra.Command{
 			return nil
 		}
 
-		if sheetsOutputFile != "" {
-			err := os.WriteFile(sheetsOutputFile, []byte(csv), 0644)
-			if err != nil {
-				return ui.ErrorWithHint(fmt.Errorf("failed to write to output file %s: %w", sheetsOutputFile, err), "Check file path permissions.")
-			}
-			ui.PrintSuccess("Saved sheet to %s", sheetsOutputFile)
-		} else {
-			fmt.Println(csv)
-		}
-		return nil
+		return writeSheetsOutput(data)
 	},
 }
 
 var sheetsGetRangeCmd = &cobra.Command{
-	Use:     "get-range [spreadsheetId]",
-	Short:   "Gets a specific range from a sheet.",
-	Long:    `Retrieves a specific range of cells from a sheet, specified using A1 notation.`,
-	Example: `  drivectl sheets get-range <spreadsheet-id> --sheet Sheet1 --range A1:C10`,
-	Args:    cobra.ExactArgs(1),
+	Use:   "get-range [spreadsheetId]",
+	Short: "Gets a specific range from a sheet.",
+	Long: `Retrieves a specific range of cells from a sheet, specified using A1 notation.
+The values are printed as aligned columns unless --format is given. The --format, --render,
+--date-time-render, --table-header and --records options work as for 'sheets get'.`,
+	Example: `  drivectl sheets get-range <spreadsheet-id> --sheet Sheet1 --range A1:C10
+  drivectl sheets get-range <spreadsheet-id> --sheet Sheet1 --range A1:C10 --format csv
+  drivectl sheets get-range <spreadsheet-id> --sheet Sheet1 --range A1:C10 --format md
+  drivectl sheets get-range <spreadsheet-id> --sheet Sheet1 --range A:A --render unformatted -O json
+  drivectl sheets get-range <spreadsheet-id> --sheet Sheet1 --range A1:D50 --records`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		spreadsheetId := args[0]
-		values, err := drive.GetSheetRange(sheetsSvc, spreadsheetId, sheetName, sheetRange)
+		values, err := drive.GetSheetRange(sheetsSvc, spreadsheetId, sheetName, sheetRange, sheetsRenderOptions())
 		if err != nil {
 			return ui.ErrorWithHint(err, "Verify the A1 notation of the range (e.g. A1:B2).")
 		}
+		if sheetsRecords {
+			return writeSheetsRecords(cmd, values, recordOptions())
+		}
+
+		if format, _ := cmd.Flags().GetString("format"); format != "" {
+			data, err := encodeSheetValues(values, format)
+			if err != nil {
+				return err
+			}
+			return writeSheetsOutput(data)
+		}
 
 		if OutputFormat == "json" {
+			if values == nil {
+				values = [][]interface{}{}
+			}
 			b, err := json.MarshalIndent(values, "", "  ")
 			if err != nil {
 				return err
@@ -117,22 +295,26 @@ var sheetsGetRangeCmd = &cobra.Command{
 			return nil
 		}
 
-		w := new(tabwriter.Writer)
-		// Format in tab-separated columns with a tab stop of 8.
-		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
-		for _, row := range values {
-			var rowStr []string
-			for _, cell := range row {
-				rowStr = append(rowStr, fmt.Sprintf("%v", cell))
-			}
-			fmt.Fprintln(w, strings.Join(rowStr, "\t"))
-		}
-		w.Flush()
-
+		printValuesTable(values)
 		return nil
 	},
 }
 
+// printValuesTable prints values as aligned columns.
+func printValuesTable(values [][]interface{}) {
+	w := new(tabwriter.Writer)
+	// Format in tab-separated columns with a tab stop of 8.
+	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
+	for _, row := range values {
+		var rowStr []string
+		for _, cell := range row {
+			rowStr = append(rowStr, drive.CellString(cell))
+		}
+		fmt.Fprintln(w, strings.Join(rowStr, "\t"))
+	}
+	w.Flush()
+}
+
 var sheetsUpdateRangeCmd = &cobra.Command{
 	Use:     "update-range [spreadsheetId] [value]",
 	Short:   "Updates a specific range in a sheet.",
@@ -150,11 +332,11 @@ var sheetsUpdateRangeCmd = &cobra.Command{
 
 		if OutputFormat == "json" {
 			res := map[string]interface{}{
-				"status": "success",
+				"status":        "success",
 				"spreadsheetId": spreadsheetId,
-				"sheetName": sheetName,
-				"range": sheetRange,
-				"updatedValue": value,
+				"sheetName":     sheetName,
+				"range":         sheetRange,
+				"updatedValue":  value,
 			}
 			b, err := json.MarshalIndent(res, "", "  ")
 			if err != nil {
@@ -169,24 +351,965 @@ var sheetsUpdateRangeCmd = &cobra.Command{
 	},
 }
 
+// readGridInput reads a grid of values from a file, or from stdin when path is
+// empty or "-". The format is csv, tsv or json; when empty it is taken from the
+// file extension, or for stdin guessed from the first character.
+func readGridInput(path string, format string) ([][]interface{}, error) {
+	var data []byte
+	var err error
+	if path == "" || path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input: %w", err)
+	}
+
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = "csv"
+		case ".tsv", ".tab":
+			format = "tsv"
+		case ".json":
+			format = "json"
+		default:
+			format = "csv"
+			if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+				format = "json"
+			}
+		}
+	}
+
+	switch format {
+	case "csv":
+		return drive.ReadCSVGrid(bytes.NewReader(data), ',')
+	case "tsv":
+		return drive.ReadCSVGrid(bytes.NewReader(data), '\t')
+	case "json":
+		return drive.ReadJSONGrid(bytes.NewReader(data))
+	}
+	return nil, fmt.Errorf("invalid input format: %s. Valid formats are: csv, tsv, json", format)
+}
+
+var sheetsImportCmd = &cobra.Command{
+	Use:   "import <spreadsheetId> [file]",
+	Short: "Imports CSV, TSV or JSON data into a sheet.",
+	Long: `Writes a grid of values into a sheet, starting at an anchor cell. The data can be CSV, TSV,
+a JSON array of arrays, or a JSON array of objects, in which case the object keys become a header
+row. When no file is given, or the file is "-", the data is read from stdin.
+
+By default values are parsed as if typed into the UI (--input user-entered), so numbers, dates
+and formulas are recognised; use --input raw to store them as plain text. Large inputs are written
+in chunks of --chunk-rows rows.`,
+	Example: `  drivectl sheets import <spreadsheet-id> data.csv --sheet Sheet1
+  drivectl sheets import <spreadsheet-id> report.json --sheet Report --create --clear
+  drivectl sheets import <spreadsheet-id> --sheet Sheet1 --anchor C5 --input raw < data.tsv
+  cat rows.json | drivectl sheets import <spreadsheet-id> - --sheet Import --input-format json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		path := ""
+		if len(args) == 2 {
+			path = args[1]
+		}
+		values, err := readGridInput(path, sheetsInputFormat)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check that the input is valid CSV, TSV or JSON, or set --input-format.")
+		}
+
+		result, err := drive.ImportValues(sheetsSvc, spreadsheetId, sheetName, values, drive.ImportOptions{
+			Anchor:    sheetsAnchor,
+			Clear:     sheetsClear,
+			Create:    sheetsCreate,
+			Input:     sheetsInput,
+			ChunkRows: sheetsChunkRows,
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check the sheet name (or pass --create), the anchor cell and that you have write permissions.")
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		if result.CreatedSheet {
+			ui.PrintSuccess("Created sheet %s", sheetName)
+		}
+		ui.PrintSuccess("Imported %d rows (%d cells) into %s", result.UpdatedRows, result.UpdatedCells, sheetName)
+		for _, r := range result.UpdatedRanges {
+			fmt.Println(ui.Muted("  " + r))
+		}
+		return nil
+	},
+}
+
+var sheetsAppendCmd = &cobra.Command{
+	Use:   "append <spreadsheetId> [json-object...]",
+	Short: "Appends JSON records as rows.",
+	Long: `Appends JSON objects as new rows below the existing data on a sheet. Each key is written to
+the column whose header in the first row matches it; keys without a column are added to the end of
+the header row. Objects are taken from the arguments or, when none are given, read from stdin as
+NDJSON (one object per line). Prints the range that was written.
+
+Records read from stdin are appended in batches of up to --batch-size rows, and whatever has
+arrived is also written once --flush-interval has passed, so a stream that stays open, such as
+the output of tail -f, is written as it arrives. The range written by each batch is printed.`,
+	Example: `  drivectl sheets append <spreadsheet-id> --sheet Log '{"time":"2025-01-31T10:00:00Z","level":"info","msg":"started"}'
+  tail -f events.ndjson | drivectl sheets append <spreadsheet-id> --sheet Events`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		if sheetsBatchSize < 1 {
+			return fmt.Errorf("invalid batch size: %d. It must be at least 1", sheetsBatchSize)
+		}
+		if sheetsFlushInterval <= 0 {
+			return fmt.Errorf("invalid flush interval: %s. The interval must be positive", sheetsFlushInterval)
+		}
+
+		appended := 0
+		write := func(records []*drive.Record) error {
+			result, err := drive.AppendRecords(sheetsSvc, spreadsheetId, sheetName, records, sheetsInput)
+			if err != nil {
+				return ui.ErrorWithHint(err, "Check the sheet name and that you have write permissions.")
+			}
+			appended += len(records)
+			return printAppendResult(result)
+		}
+
+		if len(args) > 1 {
+			var records []*drive.Record
+			for i, arg := range args[1:] {
+				rec, err := drive.ParseRecord([]byte(arg))
+				if err != nil {
+					return fmt.Errorf("unable to parse record %d: %w", i+1, err)
+				}
+				records = append(records, rec)
+			}
+			return write(records)
+		}
+
+		if err := appendStream(os.Stdin, write); err != nil {
+			if appended > 0 {
+				return fmt.Errorf("%w (%d rows were appended before the error)", err, appended)
+			}
+			return err
+		}
+		if appended == 0 {
+			return ui.ErrorWithHint(fmt.Errorf("no records to append"), "Pass JSON objects as arguments or pipe NDJSON on stdin.")
+		}
+		return nil
+	},
+}
+
+// appendStream reads NDJSON records from r and passes them to write in
+// batches of up to --batch-size records. A batch is also written once
+// --flush-interval has passed since its first record arrived, so that a
+// stream that never ends is written as it goes.
+func appendStream(r io.Reader, write func(records []*drive.Record) error) error {
+	records := make(chan *drive.Record)
+	scanErr := make(chan error, 1)
+	go func() {
+		scanErr <- drive.ScanNDJSONRecords(r, func(rec *drive.Record) error {
+			records <- rec
+			return nil
+		})
+		close(records)
+	}()
+
+	var batch []*drive.Record
+	var flush <-chan time.Time
+	for {
+		select {
+		case rec, ok := <-records:
+			if !ok {
+				if err := <-scanErr; err != nil {
+					return err
+				}
+				if len(batch) == 0 {
+					return nil
+				}
+				return write(batch)
+			}
+			batch = append(batch, rec)
+			if len(batch) == 1 {
+				flush = time.After(sheetsFlushInterval)
+			}
+			if len(batch) < sheetsBatchSize {
+				continue
+			}
+		case <-flush:
+		}
+		if err := write(batch); err != nil {
+			return err
+		}
+		batch, flush = nil, nil
+	}
+}
+
+// printAppendResult prints the outcome of one append.
+func printAppendResult(result *drive.AppendResult) error {
+	if OutputFormat == "json" {
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	if len(result.AddedColumns) > 0 {
+		ui.PrintSuccess("Added columns: %s", strings.Join(result.AddedColumns, ", "))
+	}
+	ui.PrintSuccess("Appended %d rows to %s", result.UpdatedRows, ui.ID(result.UpdatedRange))
+	return nil
+}
+
+// printSheetResult prints the result of a tab operation.
+func printSheetResult(v interface{}, msg string, args ...interface{}) error {
+	if OutputFormat == "json" {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	ui.PrintSuccess(msg, args...)
+	return nil
+}
+
+const sheetsTabHint = "Check the spreadsheet ID and tab name with 'drivectl sheets list', and that you have edit access."
+
+var sheetsCreateCmd = &cobra.Command{
+	Use:   "create <title> [csv-file...]",
+	Short: "Creates a spreadsheet.",
+	Long: `Creates a new spreadsheet. Each CSV, TSV or JSON file given becomes a tab named after the file,
+filled with the file's data.`,
+	Example: `  drivectl sheets create "Q3 Budget"
+  drivectl sheets create "Q3 Report" revenue.csv costs.csv`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		title, files := args[0], args[1:]
+		var tabs []string
+		var grids [][][]interface{}
+		for _, file := range files {
+			values, err := readGridInput(file, "")
+			if err != nil {
+				return ui.ErrorWithHint(fmt.Errorf("%s: %w", file, err), "Check that each file is valid CSV, TSV or JSON.")
+			}
+			tabs = append(tabs, strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+			grids = append(grids, values)
+		}
+
+		spreadsheet, err := drive.CreateSpreadsheet(sheetsSvc, title, tabs)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Tab names must be unique; rename files that share a name.")
+		}
+		for i, tab := range tabs {
+			_, err := drive.ImportValues(sheetsSvc, spreadsheet.SpreadsheetId, tab, grids[i], drive.ImportOptions{Input: sheetsInput})
+			if err != nil {
+				return ui.ErrorWithHint(err, fmt.Sprintf("The spreadsheet %s was created, but seeding tab %s failed.", spreadsheet.SpreadsheetId, tab))
+			}
+		}
+
+		res := map[string]interface{}{
+			"spreadsheetId": spreadsheet.SpreadsheetId,
+			"title":         title,
+			"url":           spreadsheet.SpreadsheetUrl,
+			"sheets":        tabs,
+		}
+		if err := printSheetResult(res, "Created spreadsheet %s %s", title, ui.ID("("+spreadsheet.SpreadsheetId+")")); err != nil {
+			return err
+		}
+		if OutputFormat != "json" {
+			fmt.Println(ui.Muted(spreadsheet.SpreadsheetUrl))
+		}
+		return nil
+	},
+}
+
+var sheetsAddTabCmd = &cobra.Command{
+	Use:     "add-tab <spreadsheetId> <title>",
+	Short:   "Adds a tab to a spreadsheet.",
+	Long:    `Adds a new, empty sheet (tab) to a spreadsheet.`,
+	Example: `  drivectl sheets add-tab <spreadsheet-id> "Summary"`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := drive.AddSheet(sheetsSvc, args[0], args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, sheetsTabHint)
+		}
+		return printSheetResult(info, "Added tab %s %s", info.Title, ui.ID(fmt.Sprintf("(%d)", info.SheetID)))
+	},
+}
+
+var sheetsRenameTabCmd = &cobra.Command{
+	Use:     "rename-tab <spreadsheetId> <tab> <new-title>",
+	Short:   "Renames a tab.",
+	Long:    `Renames a sheet (tab), given its current title or numeric sheet ID.`,
+	Example: `  drivectl sheets rename-tab <spreadsheet-id> Sheet1 "Raw Data"`,
+	Args:    cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := drive.FindSheet(sheetsSvc, args[0], args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, sheetsTabHint)
+		}
+		if err := drive.RenameSheet(sheetsSvc, args[0], info.SheetID, args[2]); err != nil {
+			return ui.ErrorWithHint(err, sheetsTabHint)
+		}
+		previous := info.Title
+		info.Title = args[2]
+		return printSheetResult(info, "Renamed tab %s to %s", previous, info.Title)
+	},
+}
+
+var sheetsDeleteTabCmd = &cobra.Command{
+	Use:   "delete-tab <spreadsheetId> <tab>",
+	Short: "Deletes a tab.",
+	Long: `Deletes a sheet (tab) and all of its data, given its title or numeric sheet ID.
+You will be asked to confirm unless --yes is given.`,
+	Example: `  drivectl sheets delete-tab <spreadsheet-id> "Old Data" --yes`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := drive.FindSheet(sheetsSvc, args[0], args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, sheetsTabHint)
+		}
+		if !sheetsYes && !confirm(fmt.Sprintf("Delete tab %s and all of its data?", info.Title)) {
+			return ui.ErrorWithHint(fmt.Errorf("aborted"), "Pass --yes to skip the confirmation prompt.")
+		}
+		if err := drive.DeleteSheet(sheetsSvc, args[0], info.SheetID); err != nil {
+			return ui.ErrorWithHint(err, "A spreadsheet must keep at least one tab.")
+		}
+		return printSheetResult(info, "Deleted tab %s", info.Title)
+	},
+}
+
+var sheetsDuplicateTabCmd = &cobra.Command{
+	Use:     "duplicate-tab <spreadsheetId> <tab>",
+	Short:   "Duplicates a tab.",
+	Long:    `Copies a sheet (tab), including its data and formatting, within the same spreadsheet.`,
+	Example: `  drivectl sheets duplicate-tab <spreadsheet-id> Template --name "2025-01"`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := drive.FindSheet(sheetsSvc, args[0], args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, sheetsTabHint)
+		}
+		copied, err := drive.DuplicateSheet(sheetsSvc, args[0], info.SheetID, sheetsTabName)
+		if err != nil {
+			return ui.ErrorWithHint(err, sheetsTabHint)
+		}
+		return printSheetResult(copied, "Duplicated tab %s as %s %s", info.Title, copied.Title, ui.ID(fmt.Sprintf("(%d)", copied.SheetID)))
+	},
+}
+
+var sheetsBatchGetCmd = &cobra.Command{
+	Use:   "batch-get <spreadsheetId> <range>...",
+	Short: "Gets several ranges in one request.",
+	Long: `Retrieves several ranges, which may be on different sheets, in a single request. Ranges use
+A1 notation and may include a sheet name (Sheet1!A1:B5); ranges without one use --sheet.
+With -O json, the output is an object keyed by the requested range.`,
+	Example: `  drivectl sheets batch-get <spreadsheet-id> "Summary!A1:B5" "Data!A1:F1"
+  drivectl sheets batch-get <spreadsheet-id> A1:B2 D1:D10 --sheet Sheet1 -O json`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		ranges := make([]string, len(args)-1)
+		for i, arg := range args[1:] {
+			rng, err := a1.Parse(arg)
+			if err != nil {
+				return ui.ErrorWithHint(err, "Use A1 notation, such as A1:B5, Sheet1!A:C or 'My Sheet'!2:5.")
+			}
+			ranges[i] = rng.Qualify(sheetName).String()
+		}
+		valueRanges, err := drive.BatchGetValues(sheetsSvc, spreadsheetId, ranges, sheetsRenderOptions())
+		if err != nil {
+			return ui.ErrorWithHint(err, "Verify the sheet names and A1 notation of each range.")
+		}
+
+		if OutputFormat == "json" {
+			res := make(map[string][][]interface{}, len(ranges))
+			for i, rng := range ranges {
+				values := valueRanges[i].Values
+				if values == nil {
+					values = [][]interface{}{}
+				}
+				res[rng] = values
+			}
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		for i, rng := range ranges {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(ui.Accent(rng + ":"))
+			printValuesTable(valueRanges[i].Values)
+		}
+		return nil
+	},
+}
+
+var sheetsBatchUpdateCmd = &cobra.Command{
+	Use:   "batch-update <spreadsheetId> [file]",
+	Short: "Updates several ranges in one request.",
+	Long: `Writes values to several ranges in a single request. The input is a JSON object mapping A1
+ranges to 2D arrays of values; a flat array is treated as one row and a scalar as one cell.
+Ranges without a sheet name use --sheet. When no file is given, or the file is "-", the JSON is
+read from stdin. With -O json, the output is an object keyed by range.`,
+	Example: `  drivectl sheets batch-update <spreadsheet-id> updates.json
+  echo '{"Summary!B2": [[42]], "Data!A1:C1": [["id", "name", "total"]]}' | drivectl sheets batch-update <spreadsheet-id>`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		in := io.Reader(os.Stdin)
+		if len(args) == 2 && args[1] != "-" {
+			f, err := os.Open(args[1])
+			if err != nil {
+				return fmt.Errorf("unable to read input: %w", err)
+			}
+			defer f.Close()
+			in = f
+		}
+		input, err := drive.ReadRangeValues(in)
+		if err != nil {
+			return ui.ErrorWithHint(err, `Provide a JSON object such as {"Sheet1!A1:B1": [["a", "b"]]}.`)
+		}
+		data := make(map[string][][]interface{}, len(input))
+		for key, values := range input {
+			rng, err := a1.Parse(key)
+			if err != nil {
+				return ui.ErrorWithHint(err, "Use A1 notation, such as A1:B5, Sheet1!A:C or 'My Sheet'!2:5.")
+			}
+			data[rng.Qualify(sheetName).String()] = values
+		}
+
+		responses, err := drive.BatchUpdateValues(sheetsSvc, spreadsheetId, data, sheetsInput)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Verify the sheet names and A1 notation of each range, and that you have write permissions.")
+		}
+
+		if OutputFormat == "json" {
+			res := make(map[string]interface{}, len(responses))
+			for _, r := range responses {
+				res[r.UpdatedRange] = map[string]interface{}{
+					"updatedRows":    r.UpdatedRows,
+					"updatedColumns": r.UpdatedColumns,
+					"updatedCells":   r.UpdatedCells,
+				}
+			}
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+
+		for _, r := range responses {
+			ui.PrintSuccess("Updated %s (%d cells)", r.UpdatedRange, r.UpdatedCells)
+		}
+		return nil
+	},
+}
+
+var sheetsQueryCmd = &cobra.Command{
+	Use:   "query <spreadsheetId> <sql>",
+	Short: "Runs a SQL query over a sheet.",
+	Long: `Reads a sheet, or a range of it with --range, and evaluates a SQL query over its rows locally.
+The header row names the columns; names are case-insensitive and names with spaces can be quoted
+with double quotes or backticks. Empty cells are NULL, and text that looks like a number,
+including values such as 1,234, $12 or 15%, is compared and summed as a number.
+
+Supported: SELECT (with AS aliases), WHERE, GROUP BY, ORDER BY ... ASC|DESC, LIMIT ... OFFSET;
+=, !=, <, <=, >, >=, LIKE (case-insensitive, % and _), IN (...), IS [NOT] NULL, AND, OR, NOT,
++ - * / %; COUNT(*), COUNT, SUM, AVG, MIN, MAX, LOWER, UPPER and LENGTH. There is no FROM clause.
+
+The result is printed as aligned columns, or in any --format. With -O json or --records, rows are
+printed as objects keyed by column.`,
+	Example: `  drivectl sheets query <spreadsheet-id> --sheet Sales "select name, sum(amount) where region = 'EU' group by name"
+  drivectl sheets query <spreadsheet-id> --sheet Sales "select * where amount > 1000 order by amount desc limit 10" --format csv
+  drivectl sheets query <spreadsheet-id> --sheet Tasks "select owner, count(*) as open where status != 'Done' group by owner" -O json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		q, err := sheetquery.Parse(args[1])
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl sheets query --help' for the supported SQL.")
+		}
+
+		values, err := drive.GetSheetRange(sheetsSvc, spreadsheetId, sheetName, sheetRange, sheetsRenderOptions())
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check the sheet name and the A1 notation of --range.")
+		}
+		if sheetsHeaderRow < 1 {
+			return fmt.Errorf("invalid header row: %d", sheetsHeaderRow)
+		}
+		if len(values) < sheetsHeaderRow {
+			return fmt.Errorf("the sheet has no header row %d", sheetsHeaderRow)
+		}
+		var header []string
+		for _, cell := range values[sheetsHeaderRow-1] {
+			header = append(header, drive.CellString(cell))
+		}
+
+		result, err := q.Run(header, values[sheetsHeaderRow:])
+		if err != nil {
+			return ui.ErrorWithHint(err, fmt.Sprintf("Available columns: %s", strings.Join(header, ", ")))
+		}
+
+		out := make([][]interface{}, 0, len(result.Rows)+1)
+		columns := make([]interface{}, len(result.Columns))
+		for i, c := range result.Columns {
+			columns[i] = c
+		}
+		out = append(out, columns)
+		out = append(out, result.Rows...)
+
+		if sheetsRecords || (OutputFormat == "json" && !cmd.Flags().Changed("format")) {
+			return writeSheetsRecords(cmd, out, drive.RecordOptions{KeepBlankRows: true})
+		}
+		if format, _ := cmd.Flags().GetString("format"); format != "" {
+			data, err := drive.EncodeValues(out, format)
+			if err != nil {
+				return err
+			}
+			return writeSheetsOutput(data)
+		}
+		printValuesTable(out)
+		return nil
+	},
+}
+
+// gridTable converts values whose header is at the given 1-based row into a table of text.
+func gridTable(values [][]interface{}, headerRow int) (*diff.Table, error) {
+	if headerRow < 1 {
+		return nil, fmt.Errorf("invalid header row: %d", headerRow)
+	}
+	t := &diff.Table{}
+	if len(values) < headerRow {
+		return t, nil
+	}
+	for _, cell := range values[headerRow-1] {
+		t.Header = append(t.Header, strings.TrimSpace(drive.CellString(cell)))
+	}
+	for _, row := range values[headerRow:] {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = drive.CellString(cell)
+		}
+		t.Rows = append(t.Rows, cells)
+	}
+	return t, nil
+}
+
+// diffBaseValues reads the source that 'sheets diff' compares the sheet against,
+// and returns it with a description for the report.
+func diffBaseValues(spreadsheetId string) ([][]interface{}, string, error) {
+	switch {
+	case sheetsWithFile != "":
+		values, err := readGridInput(sheetsWithFile, "")
+		return values, sheetsWithFile, err
+	case sheetsWithRevision != "":
+		info, err := drive.FindSheet(sheetsSvc, spreadsheetId, sheetName)
+		if err != nil {
+			return nil, "", err
+		}
+		values, err := drive.GetSheetRevisionValues(driveSvc, client, spreadsheetId, sheetsWithRevision, info.SheetID)
+		return values, fmt.Sprintf("%s@%s", sheetName, sheetsWithRevision), err
+	}
+
+	baseSpreadsheet, baseSheet := spreadsheetId, sheetName
+	if sheetsWithSpreadsheet != "" {
+		baseSpreadsheet = sheetsWithSpreadsheet
+	}
+	if sheetsWithSheet != "" {
+		baseSheet = sheetsWithSheet
+	}
+	values, err := drive.GetSheetRange(sheetsSvc, baseSpreadsheet, baseSheet, sheetRange, sheetsRenderOptions())
+	label := baseSheet
+	if baseSpreadsheet != spreadsheetId {
+		label = baseSpreadsheet + "/" + baseSheet
+	}
+	return values, label, err
+}
+
+var sheetsDiffCmd = &cobra.Command{
+	Use:   "diff <spreadsheetId>",
+	Short: "Compares a sheet with another tab, spreadsheet, file or revision.",
+	Long: `Compares a sheet with another source and reports the rows that were added, removed or changed,
+and the cells that changed within them. Rows are matched by the value in the --key column, and
+columns by their header. The report shows how --sheet differs from the other source, which is one of:
+
+  --with-sheet         another tab in the same spreadsheet
+  --with-spreadsheet   the same tab (or --with-sheet) in another spreadsheet
+  --with-file          a local CSV, TSV or JSON file
+  --with-revision      an earlier revision of the same tab
+
+The report is a coloured table by default. Use --format json for a structured report, or
+--format patch for a JSON Patch (RFC 6902) over an object keyed by the key column.`,
+	Example: `  drivectl sheets diff <spreadsheet-id> --sheet Tracker --with-sheet "Tracker (old)" --key id
+  drivectl sheets diff <spreadsheet-id> --sheet Tracker --with-file export.csv --key id
+  drivectl sheets diff <spreadsheet-id> --sheet Tracker --with-revision <revision-id> --key id --format patch
+  drivectl sheets diff <spreadsheet-id> --sheet Tracker --with-spreadsheet <other-id> --key id -O json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		sources := 0
+		for _, set := range []bool{sheetsWithFile != "", sheetsWithRevision != "", sheetsWithSheet != "" || sheetsWithSpreadsheet != ""} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return ui.ErrorWithHint(fmt.Errorf("choose one source to compare with"), "Pass --with-sheet and/or --with-spreadsheet, --with-file, or --with-revision.")
+		}
+
+		values, err := drive.GetSheetRange(sheetsSvc, spreadsheetId, sheetName, sheetRange, sheetsRenderOptions())
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check the sheet name and the A1 notation of --range.")
+		}
+		baseValues, baseLabel, err := diffBaseValues(spreadsheetId)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check the source given with --with-sheet, --with-spreadsheet, --with-file or --with-revision.")
+		}
+
+		to, err := gridTable(values, sheetsHeaderRow)
+		if err != nil {
+			return err
+		}
+		from, err := gridTable(baseValues, sheetsHeaderRow)
+		if err != nil {
+			return err
+		}
+		d, err := diff.CompareTables(from, to, sheetsKey)
+		if err != nil {
+			return ui.ErrorWithHint(err, "The --key column must exist in both sources and hold a unique value per row.")
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if OutputFormat == "json" && !cmd.Flags().Changed("format") {
+			format = "json"
+		}
+		var report []byte
+		switch format {
+		case "json":
+			report, err = json.MarshalIndent(d, "", "  ")
+		case "patch":
+			report, err = json.MarshalIndent(d.Patch(), "", "  ")
+		case "", "table":
+			printTableDiff(d, baseLabel, sheetName)
+			return nil
+		default:
+			return fmt.Errorf("invalid format: %s. Valid formats are: table, json, patch", format)
+		}
+		if err != nil {
+			return err
+		}
+		return writeSheetsOutput(string(report) + "\n")
+	},
+}
+
+func printTableDiff(d *diff.TableDiff, fromLabel, toLabel string) {
+	fmt.Println(ui.Accent(fmt.Sprintf("Comparing %s → %s by %s:", fromLabel, toLabel, d.Key)))
+	if d.Empty() {
+		fmt.Println(ui.Pass("No differences found."))
+		return
+	}
+	for _, c := range d.AddedColumns {
+		fmt.Printf("%s column %s\n", ui.Pass("+"), c)
+	}
+	for _, c := range d.RemovedColumns {
+		fmt.Printf("%s column %s\n", ui.Fail("-"), c)
+	}
+	for _, r := range d.Added {
+		fmt.Printf("%s %s %s\n", ui.Pass("+"), r.Key, ui.Muted(fmt.Sprintf("(row %d)", r.Row)))
+	}
+	for _, r := range d.Removed {
+		fmt.Printf("%s %s %s\n", ui.Fail("-"), r.Key, ui.Muted(fmt.Sprintf("(was row %d)", r.Row)))
+	}
+	for _, r := range d.Changed {
+		fmt.Printf("%s %s %s\n", ui.Warn("~"), r.Key, ui.Muted(fmt.Sprintf("(row %d)", r.Row)))
+		for _, c := range r.Cells {
+			fmt.Printf("    %s: %s → %s\n", c.Column, ui.Fail(c.From), ui.Pass(c.To))
+		}
+	}
+	fmt.Println(ui.Muted(fmt.Sprintf("%d added, %d removed, %d changed", len(d.Added), len(d.Removed), len(d.Changed))))
+}
+
+var sheetsUpsertCmd = &cobra.Command{
+	Use:   "upsert <spreadsheetId> [file]",
+	Short: "Updates and appends rows matched by a key column.",
+	Long: `Merges CSV, TSV or JSON rows into a sheet without overwriting rows that are not in the input.
+Rows are matched by the value in the --key column, and columns by the header in the first row of
+the input and of the sheet. Cells that differ are updated, rows whose key is not on the sheet are
+appended, and input columns the sheet lacks are added to its header row. Columns that are not in
+the input are left alone. With --delete-missing, sheet rows whose key is not in the input are
+deleted, after confirmation unless --yes is given.
+
+The changes are written with at most three requests, and --dry-run shows them without writing.
+Values are compared as displayed; use --render unformatted to compare numbers without their
+formatting. When no file is given, or the file is "-", the data is read from stdin.`,
+	Example: `  drivectl sheets upsert <spreadsheet-id> data.csv --sheet Customers --key id
+  drivectl sheets upsert <spreadsheet-id> data.csv --sheet Customers --key id --delete-missing --dry-run
+  export-customers | drivectl sheets upsert <spreadsheet-id> --sheet Customers --key id -O json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		path := ""
+		if len(args) == 2 {
+			path = args[1]
+		}
+		values, err := readGridInput(path, sheetsInputFormat)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check that the input is valid CSV, TSV or JSON, or set --input-format.")
+		}
+
+		plan, err := drive.PlanUpsert(sheetsSvc, spreadsheetId, sheetName, values, drive.UpsertOptions{
+			Key:           sheetsKey,
+			DeleteMissing: sheetsDeleteMissing,
+			Input:         sheetsInput,
+			Read:          sheetsRenderOptions(),
+		})
+		if err != nil {
+			return ui.ErrorWithHint(err, "The --key column must exist in the input and on the sheet, and hold a unique value per row.")
+		}
+		result := plan.Result
+
+		if !sheetsDryRun && len(result.Deleted) > 0 && !sheetsYes &&
+			!confirm(fmt.Sprintf("Delete %d rows from %s that are not in the input?", len(result.Deleted), sheetName)) {
+			return ui.ErrorWithHint(fmt.Errorf("aborted"), "Pass --yes to skip the confirmation prompt, or --dry-run to preview the changes.")
+		}
+		if !sheetsDryRun {
+			if err := plan.Apply(sheetsSvc); err != nil {
+				return ui.ErrorWithHint(err, "Check that you have write permissions.")
+			}
+		}
+
+		if OutputFormat == "json" {
+			b, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		printUpsertResult(result)
+		return nil
+	},
+}
+
+func printUpsertResult(r *drive.UpsertResult) {
+	if sheetsDryRun {
+		fmt.Println(ui.Accent(fmt.Sprintf("Changes to %s (dry run):", r.Sheet)))
+	}
+	for _, c := range r.AddedColumns {
+		fmt.Printf("%s column %s\n", ui.Pass("+"), c)
+	}
+	for _, row := range r.Updated {
+		fmt.Printf("%s %s %s\n", ui.Warn("~"), row.Key, ui.Muted(fmt.Sprintf("(row %d)", row.Row)))
+		for _, c := range row.Cells {
+			fmt.Printf("    %s %s: %s → %s\n", ui.Muted(c.Cell), c.Column, ui.Fail(c.From), ui.Pass(c.To))
+		}
+	}
+	for _, row := range r.Appended {
+		fmt.Printf("%s %s\n", ui.Pass("+"), row.Key)
+	}
+	for _, row := range r.Deleted {
+		fmt.Printf("%s %s %s\n", ui.Fail("-"), row.Key, ui.Muted(fmt.Sprintf("(row %d)", row.Row)))
+	}
+	summary := fmt.Sprintf("%d updated, %d appended, %d deleted, %d unchanged", len(r.Updated), len(r.Appended), len(r.Deleted), r.Unchanged)
+	switch {
+	case sheetsDryRun:
+		fmt.Println(ui.Muted(fmt.Sprintf("%s (%d requests)", summary, r.Requests)))
+	case r.Requests == 0:
+		ui.PrintSuccess("%s is up to date (%s)", r.Sheet, summary)
+	default:
+		ui.PrintSuccess("Upserted into %s: %s", r.Sheet, summary)
+	}
+}
+
+var sheetsFormatCmd = &cobra.Command{
+	Use:   "format <spreadsheetId> [spec-file]",
+	Short: "Styles a sheet from a YAML or JSON spec.",
+	Long: `Applies formatting described in a YAML or JSON spec file to a sheet, in a single batchUpdate.
+When no file is given, or the file is "-", the spec is read from stdin. Unknown keys are an error.
+
+  headerRows: 1                  # rows treated as the header (default 1)
+  header: {bold: true, background: "#e8eaed"}
+  freeze: {rows: 1, columns: 1}
+  autoResize: true               # fit every column to its contents
+  columns:                       # by A1 column range or by header name
+    - {range: "A", width: 80}
+    - {name: amount, numberFormat: "#,##0.00", align: right}
+    - {name: due, dateFormat: "yyyy-mm-dd", autoResize: true}
+  ranges:
+    - {range: "A2:A", italic: true, color: "#5f6368"}
+  replaceConditional: true       # remove the sheet's existing conditional rules first
+  conditional:
+    - {range: "C2:C", condition: gt, values: ["1000"], background: "#d9ead3"}
+    - {range: "A2:D", condition: formula, values: ["=$D2=\"Late\""], color: "#cc0000"}
+
+Styles accept bold, italic, color, background, align (left, center, right), numberFormat (a
+pattern, or number, percent, currency, date, time, datetime, scientific or text) and dateFormat.
+Column styles apply below the header rows. Conditions are gt, gte, lt, lte, eq, ne, between,
+not-between, contains, not-contains, starts-with, ends-with, text-eq, blank, not-blank and formula.
+Use --dry-run to print the requests without applying them.`,
+	Example: `  drivectl sheets format <spreadsheet-id> style.yaml --sheet Report
+  drivectl sheets format <spreadsheet-id> style.json --sheet Report --dry-run
+  echo '{"header": {"bold": true}, "freeze": {"rows": 1}, "autoResize": true}' | drivectl sheets format <spreadsheet-id> --sheet Report`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadsheetId := args[0]
+		in := io.Reader(os.Stdin)
+		if len(args) == 2 && args[1] != "-" {
+			f, err := os.Open(args[1])
+			if err != nil {
+				return fmt.Errorf("unable to read format spec: %w", err)
+			}
+			defer f.Close()
+			in = f
+		}
+		spec, err := drive.ParseFormatSpec(in)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Run 'drivectl sheets format --help' for the spec format.")
+		}
+
+		reqs, err := drive.FormatSheet(sheetsSvc, spreadsheetId, sheetName, spec, sheetsDryRun)
+		if err != nil {
+			return ui.ErrorWithHint(err, "Check the spec, the sheet name and that you have edit access.")
+		}
+
+		if sheetsDryRun || OutputFormat == "json" {
+			b, err := json.MarshalIndent(map[string]interface{}{
+				"spreadsheetId": spreadsheetId,
+				"sheet":         sheetName,
+				"applied":       !sheetsDryRun,
+				"requests":      reqs,
+			}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		ui.PrintSuccess("Applied %d formatting requests to %s", len(reqs), sheetName)
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(sheetsCmd)
 	sheetsCmd.AddCommand(sheetsListCmd)
 	sheetsCmd.AddCommand(sheetsGetCmd)
 	sheetsCmd.AddCommand(sheetsGetRangeCmd)
 	sheetsCmd.AddCommand(sheetsUpdateRangeCmd)
+	sheetsCmd.AddCommand(sheetsImportCmd)
+	sheetsCmd.AddCommand(sheetsAppendCmd)
+	sheetsCmd.AddCommand(sheetsCreateCmd)
+	sheetsCmd.AddCommand(sheetsAddTabCmd)
+	sheetsCmd.AddCommand(sheetsRenameTabCmd)
+	sheetsCmd.AddCommand(sheetsDeleteTabCmd)
+	sheetsCmd.AddCommand(sheetsDuplicateTabCmd)
+	sheetsCmd.AddCommand(sheetsBatchGetCmd)
+	sheetsCmd.AddCommand(sheetsBatchUpdateCmd)
+	sheetsCmd.AddCommand(sheetsQueryCmd)
+	sheetsCmd.AddCommand(sheetsDiffCmd)
+	sheetsCmd.AddCommand(sheetsUpsertCmd)
+	sheetsCmd.AddCommand(sheetsFormatCmd)
 
 	sheetsGetCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to get")
 	_ = sheetsGetCmd.MarkFlagRequired("sheet")
-	sheetsGetCmd.Flags().StringVarP(&sheetsOutputFile, "output", "o", "", "Path to save the output file")
+	addSheetsReadFlags(sheetsGetCmd, "csv")
 
 	sheetsGetRangeCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet")
 	_ = sheetsGetRangeCmd.MarkFlagRequired("sheet")
 	sheetsGetRangeCmd.Flags().StringVar(&sheetRange, "range", "", "The A1 notation of the range to retrieve")
 	_ = sheetsGetRangeCmd.MarkFlagRequired("range")
+	addSheetsReadFlags(sheetsGetRangeCmd, "")
 
 	sheetsUpdateRangeCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet")
 	_ = sheetsUpdateRangeCmd.MarkFlagRequired("sheet")
 	sheetsUpdateRangeCmd.Flags().StringVar(&sheetRange, "range", "", "The A1 notation of the range to update")
 	_ = sheetsUpdateRangeCmd.MarkFlagRequired("range")
+
+	sheetsImportCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to write to")
+	_ = sheetsImportCmd.MarkFlagRequired("sheet")
+	sheetsImportCmd.Flags().StringVar(&sheetsAnchor, "anchor", "A1", "Top-left cell to write from")
+	sheetsImportCmd.Flags().BoolVar(&sheetsClear, "clear", false, "Clear all values on the sheet before writing")
+	sheetsImportCmd.Flags().BoolVar(&sheetsCreate, "create", false, "Create the sheet if it does not exist")
+	sheetsImportCmd.Flags().StringVar(&sheetsInput, "input", "user-entered", "How values are interpreted (user-entered, raw)")
+	sheetsImportCmd.Flags().StringVar(&sheetsInputFormat, "input-format", "", "Input format (csv, tsv, json); inferred when empty")
+	sheetsImportCmd.Flags().IntVar(&sheetsChunkRows, "chunk-rows", 1000, "Maximum rows written per request")
+
+	sheetsAppendCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to append to")
+	_ = sheetsAppendCmd.MarkFlagRequired("sheet")
+	sheetsAppendCmd.Flags().StringVar(&sheetsInput, "input", "user-entered", "How values are interpreted (user-entered, raw)")
+	sheetsAppendCmd.Flags().IntVar(&sheetsBatchSize, "batch-size", 500, "Maximum records from stdin written per request")
+	sheetsAppendCmd.Flags().DurationVar(&sheetsFlushInterval, "flush-interval", 5*time.Second, "Longest time records from stdin wait before they are written")
+
+	sheetsCreateCmd.Flags().StringVar(&sheetsInput, "input", "user-entered", "How seeded values are interpreted (user-entered, raw)")
+	sheetsDeleteTabCmd.Flags().BoolVarP(&sheetsYes, "yes", "y", false, "Do not prompt for confirmation")
+	sheetsDuplicateTabCmd.Flags().StringVar(&sheetsTabName, "name", "", "Title for the copy (defaults to \"Copy of <tab>\")")
+
+	sheetsBatchGetCmd.Flags().StringVar(&sheetName, "sheet", "", "Sheet for ranges that do not name one")
+	sheetsBatchGetCmd.Flags().StringVar(&sheetsRender, "render", "formatted", "How values are rendered (formatted, unformatted, formula)")
+	sheetsBatchGetCmd.Flags().StringVar(&sheetsDateTimeRender, "date-time-render", "serial", "How unformatted dates are rendered (serial, formatted)")
+
+	sheetsBatchUpdateCmd.Flags().StringVar(&sheetName, "sheet", "", "Sheet for ranges that do not name one")
+	sheetsBatchUpdateCmd.Flags().StringVar(&sheetsInput, "input", "user-entered", "How values are interpreted (user-entered, raw)")
+
+	sheetsQueryCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to query")
+	_ = sheetsQueryCmd.MarkFlagRequired("sheet")
+	sheetsQueryCmd.Flags().StringVar(&sheetRange, "range", "", "A1 range within the sheet to query (defaults to the whole sheet)")
+	sheetsQueryCmd.Flags().IntVar(&sheetsHeaderRow, "header-row", 1, "Row within the data that holds the column names")
+	sheetsQueryCmd.Flags().String("format", "", "Output format (csv, tsv, json, ndjson, md, html)")
+	sheetsQueryCmd.Flags().BoolVar(&sheetsRecords, "records", false, "Output rows as objects keyed by column (json or ndjson)")
+	sheetsQueryCmd.Flags().StringVar(&sheetsRender, "render", "formatted", "How values are read (formatted, unformatted, formula)")
+	sheetsQueryCmd.Flags().StringVar(&sheetsDateTimeRender, "date-time-render", "serial", "How unformatted dates are read (serial, formatted)")
+	sheetsQueryCmd.Flags().StringVarP(&sheetsOutputFile, "output", "o", "", "Path to save the output file")
+
+	sheetsDiffCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to compare")
+	_ = sheetsDiffCmd.MarkFlagRequired("sheet")
+	sheetsDiffCmd.Flags().StringVar(&sheetsKey, "key", "", "Header of the column that identifies each row")
+	_ = sheetsDiffCmd.MarkFlagRequired("key")
+	sheetsDiffCmd.Flags().StringVar(&sheetRange, "range", "", "A1 range within the sheets to compare (defaults to the whole sheet)")
+	sheetsDiffCmd.Flags().IntVar(&sheetsHeaderRow, "header-row", 1, "Row within the data that holds the column names")
+	sheetsDiffCmd.Flags().StringVar(&sheetsWithSheet, "with-sheet", "", "Compare with this tab")
+	sheetsDiffCmd.Flags().StringVar(&sheetsWithSpreadsheet, "with-spreadsheet", "", "Compare with this spreadsheet")
+	sheetsDiffCmd.Flags().StringVar(&sheetsWithFile, "with-file", "", "Compare with a local CSV, TSV or JSON file")
+	sheetsDiffCmd.Flags().StringVar(&sheetsWithRevision, "with-revision", "", "Compare with this revision of the sheet")
+	sheetsDiffCmd.Flags().String("format", "", "Report format (table, json, patch)")
+	sheetsDiffCmd.Flags().StringVar(&sheetsRender, "render", "formatted", "How values are read (formatted, unformatted, formula)")
+	sheetsDiffCmd.Flags().StringVar(&sheetsDateTimeRender, "date-time-render", "serial", "How unformatted dates are read (serial, formatted)")
+	sheetsDiffCmd.Flags().StringVarP(&sheetsOutputFile, "output", "o", "", "Path to save the report")
+
+	sheetsUpsertCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to write to")
+	_ = sheetsUpsertCmd.MarkFlagRequired("sheet")
+	sheetsUpsertCmd.Flags().StringVar(&sheetsKey, "key", "", "Header of the column that identifies each row")
+	_ = sheetsUpsertCmd.MarkFlagRequired("key")
+	sheetsUpsertCmd.Flags().BoolVar(&sheetsDeleteMissing, "delete-missing", false, "Delete sheet rows whose key is not in the input")
+	sheetsUpsertCmd.Flags().BoolVar(&sheetsDryRun, "dry-run", false, "Show the changes without writing them")
+	sheetsUpsertCmd.Flags().BoolVarP(&sheetsYes, "yes", "y", false, "Do not prompt before deleting rows")
+	sheetsUpsertCmd.Flags().StringVar(&sheetsInput, "input", "user-entered", "How values are interpreted (user-entered, raw)")
+	sheetsUpsertCmd.Flags().StringVar(&sheetsInputFormat, "input-format", "", "Input format (csv, tsv, json); inferred when empty")
+	sheetsUpsertCmd.Flags().StringVar(&sheetsRender, "render", "formatted", "How current values are read for comparison (formatted, unformatted, formula)")
+	sheetsUpsertCmd.Flags().StringVar(&sheetsDateTimeRender, "date-time-render", "serial", "How unformatted dates are read (serial, formatted)")
+
+	sheetsFormatCmd.Flags().StringVar(&sheetName, "sheet", "", "Name of the sheet to format")
+	_ = sheetsFormatCmd.MarkFlagRequired("sheet")
+	sheetsFormatCmd.Flags().BoolVar(&sheetsDryRun, "dry-run", false, "Print the batchUpdate requests without applying them")
 }
@@ -0,0 +1,439 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package a1 parses and formats spreadsheet references in A1 and R1C1
+// notation, such as 'Q1 Sales'!A2:D, Sheet1!B:C, 2:5 or R2C1:R10C4.
+package a1
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// Cell is a single cell, with zero-based column and row indexes.
+type Cell struct {
+	Col int
+	Row int
+}
+
+// String returns the cell in A1 notation, such as B12.
+func (c Cell) String() string {
+	return ColumnName(c.Col) + strconv.Itoa(c.Row+1)
+}
+
+// In returns the one-cell range at c on a sheet.
+func (c Cell) In(sheet string) Range {
+	return Range{Sheet: sheet, StartCol: c.Col, StartRow: c.Row, EndCol: c.Col + 1, EndRow: c.Row + 1}
+}
+
+// ParseCell parses a cell in A1 notation, such as B12 or $B$12.
+func ParseCell(ref string) (Cell, error) {
+	p, ok := parseA1Part(ref)
+	if !ok || p.col < 0 || p.row < 0 {
+		return Cell{}, fmt.Errorf("invalid cell reference: %q", ref)
+	}
+	return Cell{Col: p.col, Row: p.row}, nil
+}
+
+// ColumnName returns the letters for a zero-based column index, such as AA for 26.
+func ColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// ColumnIndex returns the zero-based index of column letters, such as 26 for AA.
+func ColumnIndex(letters string) (int, error) {
+	if letters == "" || len(letters) > 3 {
+		return 0, fmt.Errorf("invalid column: %q", letters)
+	}
+	n := 0
+	for _, r := range strings.ToUpper(letters) {
+		if r < 'A' || r > 'Z' {
+			return 0, fmt.Errorf("invalid column: %q", letters)
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1, nil
+}
+
+// Range is a rectangular range, optionally on a named sheet. As in a Sheets
+// GridRange, start indexes are zero-based and inclusive, end indexes are
+// exclusive, and an end of zero leaves the range unbounded in that direction:
+// B:C has no row bounds, 2:5 no column bounds, and a Range with only Sheet
+// set covers the whole sheet.
+type Range struct {
+	Sheet    string
+	StartCol int
+	StartRow int
+	EndCol   int
+	EndRow   int
+}
+
+// Sheet returns the range covering a whole sheet.
+func Sheet(name string) Range {
+	return Range{Sheet: name}
+}
+
+// Covering returns the range covered by a grid of values written with its
+// top-left corner at anchor. Rows may have different lengths; an empty grid
+// covers the anchor cell alone.
+func Covering(sheet string, anchor Cell, values [][]interface{}) Range {
+	cols := 0
+	for _, row := range values {
+		cols = max(cols, len(row))
+	}
+	return Range{
+		Sheet:    sheet,
+		StartCol: anchor.Col,
+		StartRow: anchor.Row,
+		EndCol:   anchor.Col + max(cols, 1),
+		EndRow:   anchor.Row + max(len(values), 1),
+	}
+}
+
+// Qualify returns r on the given sheet when it does not name a sheet itself.
+func (r Range) Qualify(sheet string) Range {
+	if r.Sheet == "" {
+		r.Sheet = sheet
+	}
+	return r
+}
+
+// Start returns the top-left cell of the range.
+func (r Range) Start() Cell {
+	return Cell{Col: r.StartCol, Row: r.StartRow}
+}
+
+// IsSheet reports whether the range covers a whole sheet.
+func (r Range) IsSheet() bool {
+	return r.StartCol == 0 && r.StartRow == 0 && r.EndCol == 0 && r.EndRow == 0
+}
+
+// GridRange converts the range to a Sheets GridRange on the given sheet ID.
+func (r Range) GridRange(sheetId int64) *sheets.GridRange {
+	return &sheets.GridRange{
+		SheetId:          sheetId,
+		StartColumnIndex: int64(r.StartCol),
+		StartRowIndex:    int64(r.StartRow),
+		EndColumnIndex:   int64(r.EndCol),
+		EndRowIndex:      int64(r.EndRow),
+	}
+}
+
+// String returns the range in A1 notation, quoting the sheet name when needed.
+func (r Range) String() string {
+	return r.format(func(col, row int) string {
+		s := ""
+		if col >= 0 {
+			s = ColumnName(col)
+		}
+		if row >= 0 {
+			s += strconv.Itoa(row + 1)
+		}
+		return s
+	})
+}
+
+// R1C1 returns the range in R1C1 notation, quoting the sheet name when needed.
+func (r Range) R1C1() string {
+	return r.format(func(col, row int) string {
+		s := ""
+		if row >= 0 {
+			s = "R" + strconv.Itoa(row+1)
+		}
+		if col >= 0 {
+			s += "C" + strconv.Itoa(col+1)
+		}
+		return s
+	})
+}
+
+// maxRows and maxColumns are the most rows and columns a sheet can have. They
+// stand in for the open end of a range that A1 notation cannot leave out.
+const (
+	maxRows    = 10000000
+	maxColumns = 18278 // ZZZ
+)
+
+// format writes the range using ref to format each end, where a negative
+// column or row is left out.
+func (r Range) format(ref func(col, row int) string) string {
+	prefix := ""
+	if r.Sheet != "" {
+		prefix = QuoteSheet(r.Sheet)
+		if r.IsSheet() {
+			return prefix
+		}
+		prefix += "!"
+	} else if r.IsSheet() {
+		return ""
+	}
+
+	// A reference can leave out the end row of whole columns, as in B2:C, but
+	// not the end column of a range that starts past the first column, nor the
+	// end row of whole rows, so those are written as the last column or row.
+	if r.EndCol == 0 && r.StartCol > 0 {
+		r.EndCol = maxColumns
+	}
+	if r.EndCol == 0 && r.EndRow == 0 {
+		r.EndRow = maxRows
+	}
+
+	switch {
+	case r.EndCol == 0 && r.StartCol == 0:
+		// Whole rows, such as 2:5.
+		return prefix + ref(-1, r.StartRow) + ":" + ref(-1, r.EndRow-1)
+	case r.EndRow == 0:
+		// Whole columns, optionally starting below the first row, such as B:C or B2:C.
+		start := ref(r.StartCol, -1)
+		if r.StartRow > 0 {
+			start = ref(r.StartCol, r.StartRow)
+		}
+		return prefix + start + ":" + ref(r.EndCol-1, -1)
+	case r.EndCol-r.StartCol == 1 && r.EndRow-r.StartRow == 1:
+		return prefix + ref(r.StartCol, r.StartRow)
+	}
+	return prefix + ref(r.StartCol, r.StartRow) + ":" + ref(r.EndCol-1, r.EndRow-1)
+}
+
+// plainSheetName matches sheet names that need no quotes.
+var plainSheetName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteSheet quotes a sheet name for use in a reference when it contains
+// anything but letters, digits and underscores, or could be read as a cell or
+// column.
+func QuoteSheet(name string) string {
+	if plainSheetName.MatchString(name) && !looksLikeReference(name) {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+func looksLikeReference(name string) bool {
+	if _, err := ColumnIndex(name); err == nil {
+		return true
+	}
+	if _, err := ParseRange(name); err == nil {
+		return true
+	}
+	_, err := ParseR1C1(name)
+	return err == nil
+}
+
+// splitSheet separates an optional sheet name from a reference. hasSheet is
+// true when the reference contains a sheet name followed by "!", or is
+// nothing but a quoted sheet name.
+func splitSheet(ref string) (sheet string, rest string, hasSheet bool, err error) {
+	if strings.HasPrefix(ref, "'") {
+		var b strings.Builder
+		for i := 1; i < len(ref); i++ {
+			if ref[i] != '\'' {
+				b.WriteByte(ref[i])
+				continue
+			}
+			// A doubled quote stands for the quote character itself.
+			if i+1 < len(ref) && ref[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			rest = ref[i+1:]
+			if rest == "" {
+				return b.String(), "", true, nil
+			}
+			if !strings.HasPrefix(rest, "!") {
+				return "", "", false, fmt.Errorf("invalid reference: %q: expected ! after the sheet name", ref)
+			}
+			return b.String(), rest[1:], true, nil
+		}
+		return "", "", false, fmt.Errorf("invalid reference: %q: unterminated quote", ref)
+	}
+	if i := strings.LastIndex(ref, "!"); i >= 0 {
+		return ref[:i], ref[i+1:], true, nil
+	}
+	return "", ref, false, nil
+}
+
+// Parse parses a reference in A1 notation. It accepts everything ParseRange
+// does, and also a sheet name on its own, such as Summary or 'Q1 Sales', which
+// covers the whole sheet. Text without "!" or ":" that is not a valid range is
+// taken to be a sheet name, as the Sheets API does.
+func Parse(ref string) (Range, error) {
+	sheet, rest, hasSheet, err := splitSheet(ref)
+	if err != nil {
+		return Range{}, err
+	}
+	if hasSheet && rest == "" {
+		if sheet == "" {
+			return Range{}, fmt.Errorf("invalid reference: %q", ref)
+		}
+		return Sheet(sheet), nil
+	}
+	r, err := parseA1(rest)
+	if err != nil {
+		if !hasSheet && ref != "" && !strings.Contains(ref, ":") {
+			return Sheet(ref), nil
+		}
+		return Range{}, err
+	}
+	r.Sheet = sheet
+	return r, nil
+}
+
+// ParseRange parses a range in A1 notation with an optional sheet name, such
+// as A1, A1:C5, B:D, 2:5, A2:C, Sheet1!A1:B2 or 'My Sheet'!B:B. Column and
+// row markers ($) are ignored, and reversed corners are put in order.
+func ParseRange(ref string) (Range, error) {
+	sheet, rest, hasSheet, err := splitSheet(ref)
+	if err != nil {
+		return Range{}, err
+	}
+	if hasSheet && sheet == "" {
+		return Range{}, fmt.Errorf("invalid range: %q: empty sheet name", ref)
+	}
+	r, err := parseA1(rest)
+	if err != nil {
+		return Range{}, err
+	}
+	r.Sheet = sheet
+	return r, nil
+}
+
+// part is one end of a range; a negative col or row means it was left out.
+type part struct {
+	col int
+	row int
+}
+
+var a1PartPattern = regexp.MustCompile(`^\$?([A-Za-z]{0,3})\$?([0-9]*)$`)
+
+func parseA1Part(s string) (part, bool) {
+	m := a1PartPattern.FindStringSubmatch(s)
+	if m == nil || m[1] == "" && m[2] == "" {
+		return part{}, false
+	}
+	p := part{col: -1, row: -1}
+	if m[1] != "" {
+		p.col, _ = ColumnIndex(m[1])
+	}
+	if m[2] != "" {
+		row, err := strconv.Atoi(m[2])
+		if err != nil || row < 1 {
+			return part{}, false
+		}
+		p.row = row - 1
+	}
+	return p, true
+}
+
+func parseA1(s string) (Range, error) {
+	r, err := rangeFromParts(s, parseA1Part)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range: %q", s)
+	}
+	return r, nil
+}
+
+var r1c1PartPattern = regexp.MustCompile(`^(?:[Rr]([0-9]+))?(?:[Cc]([0-9]+))?$`)
+
+func parseR1C1Part(s string) (part, bool) {
+	m := r1c1PartPattern.FindStringSubmatch(s)
+	if m == nil || m[1] == "" && m[2] == "" {
+		return part{}, false
+	}
+	p := part{col: -1, row: -1}
+	for i, dst := range []*int{&p.row, &p.col} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil || n < 1 {
+			return part{}, false
+		}
+		*dst = n - 1
+	}
+	return p, true
+}
+
+// ParseR1C1 parses a range in absolute R1C1 notation with an optional sheet
+// name, such as R1C1, R2C1:R10C4, R2:R5, C2:C3 or 'My Sheet'!R1C1:R1C5.
+// Relative references such as R[1]C[1] are not supported.
+func ParseR1C1(ref string) (Range, error) {
+	sheet, rest, hasSheet, err := splitSheet(ref)
+	if err != nil {
+		return Range{}, err
+	}
+	if hasSheet && sheet == "" {
+		return Range{}, fmt.Errorf("invalid range: %q: empty sheet name", ref)
+	}
+	if strings.Contains(rest, "[") {
+		return Range{}, fmt.Errorf("invalid range: %q: relative R1C1 references are not supported", ref)
+	}
+	r, err := rangeFromParts(rest, parseR1C1Part)
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid R1C1 range: %q", rest)
+	}
+	r.Sheet = sheet
+	return r, nil
+}
+
+// rangeFromParts builds a range from one or two ends parsed by parsePart. A
+// single end must be a cell; with two ends, both must have columns or both
+// must have rows, and ends that leave out a row or column leave the range
+// unbounded in that direction.
+func rangeFromParts(s string, parsePart func(string) (part, bool)) (Range, error) {
+	startText, endText, isPair := strings.Cut(s, ":")
+	start, ok := parsePart(startText)
+	if !ok {
+		return Range{}, fmt.Errorf("invalid range")
+	}
+	if !isPair {
+		if start.col < 0 || start.row < 0 {
+			return Range{}, fmt.Errorf("invalid range")
+		}
+		return Cell{Col: start.col, Row: start.row}.In(""), nil
+	}
+	end, ok := parsePart(endText)
+	if !ok {
+		return Range{}, fmt.Errorf("invalid range")
+	}
+
+	var r Range
+	switch {
+	case start.col >= 0 && end.col >= 0:
+		r.StartCol, r.EndCol = min(start.col, end.col), max(start.col, end.col)+1
+		if start.row >= 0 && end.row >= 0 {
+			r.StartRow, r.EndRow = min(start.row, end.row), max(start.row, end.row)+1
+		} else if start.row >= 0 {
+			r.StartRow = start.row
+		} else if end.row >= 0 {
+			r.EndRow = end.row + 1
+		}
+	case start.col < 0 && end.col < 0:
+		if start.row < 0 || end.row < 0 {
+			return Range{}, fmt.Errorf("invalid range")
+		}
+		r.StartRow, r.EndRow = min(start.row, end.row), max(start.row, end.row)+1
+	default:
+		return Range{}, fmt.Errorf("invalid range")
+	}
+	return r, nil
+}
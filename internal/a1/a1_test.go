@@ -0,0 +1,329 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package a1
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want Range
+		// str is the expected String of the parsed range, when it differs from ref.
+		str string
+	}{
+		{ref: "A1", want: Range{StartCol: 0, StartRow: 0, EndCol: 1, EndRow: 1}},
+		{ref: "B12", want: Range{StartCol: 1, StartRow: 11, EndCol: 2, EndRow: 12}},
+		{ref: "A1:C5", want: Range{StartCol: 0, StartRow: 0, EndCol: 3, EndRow: 5}},
+		{ref: "C5:A1", want: Range{StartCol: 0, StartRow: 0, EndCol: 3, EndRow: 5}, str: "A1:C5"},
+		{ref: "A5:C1", want: Range{StartCol: 0, StartRow: 0, EndCol: 3, EndRow: 5}, str: "A1:C5"},
+		{ref: "$A$1", want: Range{StartCol: 0, StartRow: 0, EndCol: 1, EndRow: 1}, str: "A1"},
+		{ref: "$A1:C$5", want: Range{StartCol: 0, StartRow: 0, EndCol: 3, EndRow: 5}, str: "A1:C5"},
+		{ref: "A:C", want: Range{StartCol: 0, EndCol: 3}},
+		{ref: "C:A", want: Range{StartCol: 0, EndCol: 3}, str: "A:C"},
+		{ref: "B:B", want: Range{StartCol: 1, EndCol: 2}},
+		{ref: "2:5", want: Range{StartRow: 1, EndRow: 5}},
+		{ref: "5:2", want: Range{StartRow: 1, EndRow: 5}, str: "2:5"},
+		{ref: "A2:C", want: Range{StartCol: 0, StartRow: 1, EndCol: 3}},
+		{ref: "aa1:ab2", want: Range{StartCol: 26, StartRow: 0, EndCol: 28, EndRow: 2}, str: "AA1:AB2"},
+		{ref: "Sheet1!A1:B2", want: Range{Sheet: "Sheet1", StartCol: 0, StartRow: 0, EndCol: 2, EndRow: 2}},
+		{ref: "'My Sheet'!B:B", want: Range{Sheet: "My Sheet", StartCol: 1, EndCol: 2}},
+		{ref: "'It''s'!A1", want: Range{Sheet: "It's", StartCol: 0, StartRow: 0, EndCol: 1, EndRow: 1}},
+		{ref: "'A1'!2:5", want: Range{Sheet: "A1", StartRow: 1, EndRow: 5}},
+		{ref: "'Q1 Sales'!A2:D", want: Range{Sheet: "Q1 Sales", StartCol: 0, StartRow: 1, EndCol: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ParseRange(tt.ref)
+			if err != nil {
+				t.Fatalf("ParseRange(%q) returned error: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRange(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+			str := tt.str
+			if str == "" {
+				str = tt.ref
+			}
+			if s := got.String(); s != str {
+				t.Errorf("ParseRange(%q).String() = %q, want %q", tt.ref, s, str)
+			}
+			again, err := ParseRange(got.String())
+			if err != nil || again != got {
+				t.Errorf("ParseRange(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, ref := range []string{
+		"",
+		"Sheet1",
+		"A",
+		"1",
+		"A0",
+		"ABCD1",
+		"A1:",
+		":A1",
+		"A:1",
+		"A1:B2:C3",
+		"!A1",
+		"'Sheet1",
+		"'Sheet1'A1",
+		"R1C1",
+	} {
+		if r, err := ParseRange(ref); err == nil {
+			t.Errorf("ParseRange(%q) = %+v, want error", ref, r)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want Range
+	}{
+		{ref: "A1:B2", want: Range{StartCol: 0, StartRow: 0, EndCol: 2, EndRow: 2}},
+		{ref: "Summary", want: Range{Sheet: "Summary"}},
+		{ref: "Sheet1", want: Range{Sheet: "Sheet1"}},
+		{ref: "'Q1 Sales'", want: Range{Sheet: "Q1 Sales"}},
+		{ref: "'It''s'", want: Range{Sheet: "It's"}},
+		{ref: "'A1'", want: Range{Sheet: "A1"}},
+		{ref: "Sheet1!", want: Range{Sheet: "Sheet1"}},
+		{ref: "Sheet1!C3", want: Range{Sheet: "Sheet1", StartCol: 2, StartRow: 2, EndCol: 3, EndRow: 3}},
+		{ref: "'My Sheet'!2:5", want: Range{Sheet: "My Sheet", StartRow: 1, EndRow: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := Parse(tt.ref)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+			again, err := Parse(got.String())
+			if err != nil || again != got {
+				t.Errorf("Parse(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+
+	for _, ref := range []string{"", "'unterminated", "A1:B2:C3", "Sheet1!A1:"} {
+		if r, err := Parse(ref); err == nil {
+			t.Errorf("Parse(%q) = %+v, want error", ref, r)
+		}
+	}
+}
+
+func TestParseR1C1(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want Range
+		// str is the expected R1C1 of the parsed range, when it differs from ref.
+		str string
+	}{
+		{ref: "R1C1", want: Range{StartCol: 0, StartRow: 0, EndCol: 1, EndRow: 1}},
+		{ref: "R2C1:R10C4", want: Range{StartCol: 0, StartRow: 1, EndCol: 4, EndRow: 10}},
+		{ref: "R10C4:R2C1", want: Range{StartCol: 0, StartRow: 1, EndCol: 4, EndRow: 10}, str: "R2C1:R10C4"},
+		{ref: "r2c3", want: Range{StartCol: 2, StartRow: 1, EndCol: 3, EndRow: 2}, str: "R2C3"},
+		{ref: "R2:R5", want: Range{StartRow: 1, EndRow: 5}},
+		{ref: "C2:C3", want: Range{StartCol: 1, EndCol: 3}},
+		{ref: "R2C1:C3", want: Range{StartCol: 0, StartRow: 1, EndCol: 3}},
+		{ref: "'My Sheet'!R1C1:R1C5", want: Range{Sheet: "My Sheet", StartCol: 0, StartRow: 0, EndCol: 5, EndRow: 1}},
+		{ref: "'It''s'!R3C2", want: Range{Sheet: "It's", StartCol: 1, StartRow: 2, EndCol: 2, EndRow: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ParseR1C1(tt.ref)
+			if err != nil {
+				t.Fatalf("ParseR1C1(%q) returned error: %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseR1C1(%q) = %+v, want %+v", tt.ref, got, tt.want)
+			}
+			str := tt.str
+			if str == "" {
+				str = tt.ref
+			}
+			if s := got.R1C1(); s != str {
+				t.Errorf("ParseR1C1(%q).R1C1() = %q, want %q", tt.ref, s, str)
+			}
+			again, err := ParseR1C1(got.R1C1())
+			if err != nil || again != got {
+				t.Errorf("ParseR1C1(%q) = %+v, %v, want %+v", got.R1C1(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestParseR1C1Errors(t *testing.T) {
+	for _, ref := range []string{
+		"",
+		"A1",
+		"R0C1",
+		"R1C0",
+		"R2",
+		"R1C1:",
+		"R[1]C[1]",
+		"R[-1]C",
+		"RC[2]",
+		"R1C1:R[2]C[3]",
+		"Sheet1!R[1]C1",
+		"!R1C1",
+	} {
+		if r, err := ParseR1C1(ref); err == nil {
+			t.Errorf("ParseR1C1(%q) = %+v, want error", ref, r)
+		}
+	}
+}
+
+func TestQuoteSheet(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Sheet1", want: "Sheet1"},
+		{name: "Summary", want: "Summary"},
+		{name: "my_data", want: "my_data"},
+		{name: "My Sheet", want: "'My Sheet'"},
+		{name: "It's", want: "'It''s'"},
+		{name: "''", want: "''''''"},
+		{name: "2024", want: "'2024'"},
+		{name: "A1", want: "'A1'"},
+		{name: "AB", want: "'AB'"},
+		{name: "R1C1", want: "'R1C1'"},
+		{name: "Q1-Sales", want: "'Q1-Sales'"},
+	}
+	for _, tt := range tests {
+		if got := QuoteSheet(tt.name); got != tt.want {
+			t.Errorf("QuoteSheet(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if r, err := Parse(tt.want); err != nil || r != Sheet(tt.name) {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v", tt.want, r, err, Sheet(tt.name))
+		}
+	}
+}
+
+func TestCovering(t *testing.T) {
+	tests := []struct {
+		name   string
+		anchor Cell
+		values [][]interface{}
+		want   string
+	}{
+		{name: "grid", anchor: Cell{Col: 0, Row: 0}, values: [][]interface{}{{1, 2}, {3, 4}}, want: "A1:B2"},
+		{name: "ragged rows", anchor: Cell{Col: 1, Row: 1}, values: [][]interface{}{{1, 2, 3}, {4}}, want: "B2:D3"},
+		{name: "single value", anchor: Cell{Col: 2, Row: 4}, values: [][]interface{}{{1}}, want: "C5"},
+		{name: "empty", anchor: Cell{Col: 2, Row: 4}, values: nil, want: "C5"},
+		{name: "empty row", anchor: Cell{Col: 0, Row: 0}, values: [][]interface{}{{}, {}}, want: "A1:A2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Covering("", tt.anchor, tt.values)
+			if s := got.String(); s != tt.want {
+				t.Errorf("Covering(%v, %v) = %q, want %q", tt.anchor, tt.values, s, tt.want)
+			}
+		})
+	}
+
+	if got := Covering("My Sheet", Cell{Col: 0, Row: 1}, [][]interface{}{{1}}).String(); got != "'My Sheet'!A2" {
+		t.Errorf("Covering on a sheet = %q, want %q", got, "'My Sheet'!A2")
+	}
+}
+
+func TestGridRange(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want *sheets.GridRange
+	}{
+		{ref: "A1", want: &sheets.GridRange{SheetId: 7, StartColumnIndex: 0, StartRowIndex: 0, EndColumnIndex: 1, EndRowIndex: 1}},
+		{ref: "B2:D5", want: &sheets.GridRange{SheetId: 7, StartColumnIndex: 1, StartRowIndex: 1, EndColumnIndex: 4, EndRowIndex: 5}},
+		{ref: "B:C", want: &sheets.GridRange{SheetId: 7, StartColumnIndex: 1, EndColumnIndex: 3}},
+		{ref: "2:5", want: &sheets.GridRange{SheetId: 7, StartRowIndex: 1, EndRowIndex: 5}},
+		{ref: "A2:C", want: &sheets.GridRange{SheetId: 7, StartColumnIndex: 0, StartRowIndex: 1, EndColumnIndex: 3}},
+		{ref: "Sheet1", want: &sheets.GridRange{SheetId: 7}},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.ref)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", tt.ref, err)
+		}
+		if got := r.GridRange(7); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q).GridRange(7) = %+v, want %+v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		r    Range
+		a1   string
+		r1c1 string
+	}{
+		{r: Range{}, a1: "", r1c1: ""},
+		{r: Sheet("Sheet1"), a1: "Sheet1", r1c1: "Sheet1"},
+		{r: Sheet("My Sheet"), a1: "'My Sheet'", r1c1: "'My Sheet'"},
+		{r: Cell{Col: 27, Row: 9}.In("Data"), a1: "Data!AB10", r1c1: "Data!R10C28"},
+		{r: Range{StartCol: 1, EndCol: 3}, a1: "B:C", r1c1: "C2:C3"},
+		{r: Range{StartRow: 1, EndRow: 5}, a1: "2:5", r1c1: "R2:R5"},
+		{r: Range{Sheet: "It's", StartCol: 0, StartRow: 1, EndCol: 3}, a1: "'It''s'!A2:C", r1c1: "'It''s'!R2C1:C3"},
+		{r: Range{StartCol: 0, StartRow: 0, EndCol: 4, EndRow: 10}, a1: "A1:D10", r1c1: "R1C1:R10C4"},
+		// Open ends that A1 notation cannot leave out run to the edge of the sheet.
+		{r: Range{StartRow: 1}, a1: "2:10000000", r1c1: "R2:R10000000"},
+		{r: Range{Sheet: "S", StartRow: 1}, a1: "'S'!2:10000000", r1c1: "'S'!R2:R10000000"},
+		{r: Range{StartCol: 2}, a1: "C:ZZZ", r1c1: "C3:C18278"},
+		{r: Range{StartCol: 2, StartRow: 1}, a1: "C2:ZZZ", r1c1: "R2C3:C18278"},
+		{r: Range{StartCol: 2, EndRow: 5}, a1: "C1:ZZZ5", r1c1: "R1C3:R5C18278"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.String(); got != tt.a1 {
+			t.Errorf("%+v.String() = %q, want %q", tt.r, got, tt.a1)
+		}
+		if got := tt.r.R1C1(); got != tt.r1c1 {
+			t.Errorf("%+v.R1C1() = %q, want %q", tt.r, got, tt.r1c1)
+		}
+		// Whatever is written must parse back to a range that is written the same way.
+		if parsed, err := Parse(tt.a1); tt.a1 != "" && (err != nil || parsed.String() != tt.a1) {
+			t.Errorf("Parse(%q) = %q, %v, want it to round-trip", tt.a1, parsed, err)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	for _, tt := range []struct {
+		index int
+		name  string
+	}{
+		{0, "A"}, {25, "Z"}, {26, "AA"}, {51, "AZ"}, {52, "BA"}, {701, "ZZ"}, {702, "AAA"}, {18277, "ZZZ"},
+	} {
+		if got := ColumnName(tt.index); got != tt.name {
+			t.Errorf("ColumnName(%d) = %q, want %q", tt.index, got, tt.name)
+		}
+		if got, err := ColumnIndex(tt.name); err != nil || got != tt.index {
+			t.Errorf("ColumnIndex(%q) = %d, %v, want %d", tt.name, got, err, tt.index)
+		}
+	}
+	for _, letters := range []string{"", "A1", "AAAA", "-"} {
+		if _, err := ColumnIndex(letters); err == nil {
+			t.Errorf("ColumnIndex(%q) succeeded, want error", letters)
+		}
+	}
+}
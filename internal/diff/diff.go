@@ -0,0 +1,318 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff computes differences between sequences of lines or words.
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Op is the kind of an edit.
+type Op int
+
+const (
+	// Equal marks an element present in both sequences.
+	Equal Op = iota
+	// Insert marks an element present only in the second sequence.
+	Insert
+	// Delete marks an element present only in the first sequence.
+	Delete
+)
+
+// Edit is a single step in the script that turns one sequence into another.
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Diff returns the shortest edit script turning a into b, using Myers' algorithm.
+// When the sequences differ by too much to search, the part between their
+// common prefix and suffix is deleted and inserted as a whole instead.
+func Diff(a, b []string) []Edit {
+	// Strip the common prefix and suffix to keep the search space small.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []Edit
+	for _, s := range a[:prefix] {
+		edits = append(edits, Edit{Equal, s})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, s := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Equal, s})
+	}
+	return edits
+}
+
+// maxDistance caps the number of insertions and deletions myers searches for.
+// The trace it keeps grows with the square of the distance, so texts that
+// differ by more than this are reported as one block replaced by another.
+const maxDistance = 2000
+
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+	limit := min(n+m, maxDistance)
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds the furthest x reached on each diagonal -d <= k <= d
+	// after d edits, which is all the backtrack needs.
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+	}
+	if !found {
+		return replace(a, b)
+	}
+
+	var edits []Edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, Edit{Insert, b[y-1]})
+		} else {
+			edits = append(edits, Edit{Delete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for ; x > 0; x-- {
+		edits = append(edits, Edit{Equal, a[x-1]})
+	}
+
+	slices.Reverse(edits)
+	return edits
+}
+
+// replace returns the edit script that deletes all of a and then inserts all of b.
+func replace(a, b []string) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, s := range a {
+		edits = append(edits, Edit{Delete, s})
+	}
+	for _, s := range b {
+		edits = append(edits, Edit{Insert, s})
+	}
+	return edits
+}
+
+// SplitLines splits text into lines, dropping the final empty line left by a trailing newline.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Unified renders the differences between two texts in unified diff format,
+// with the given number of context lines around each change. It returns an
+// empty string when the texts are identical.
+func Unified(fromName, toName string, from, to string, context int) string {
+	edits := Diff(SplitLines(from), SplitLines(to))
+
+	// aPos[i] and bPos[i] are the 0-based line numbers before edit i.
+	aPos := make([]int, len(edits)+1)
+	bPos := make([]int, len(edits)+1)
+	var changes []int
+	for i, e := range edits {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if e.Op != Insert {
+			aPos[i+1]++
+		}
+		if e.Op != Delete {
+			bPos[i+1]++
+		}
+		if e.Op != Equal {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for i := 0; i < len(changes); {
+		// Merge changes whose context would overlap into one hunk.
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context {
+			j++
+		}
+		start := changes[i] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		aLen, bLen := aPos[end]-aPos[start], bPos[end]-bPos[start]
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aPos[start], aLen), hunkRange(bPos[start], bLen))
+		for _, e := range edits[start:end] {
+			switch e.Op {
+			case Equal:
+				out.WriteString(" ")
+			case Insert:
+				out.WriteString("+")
+			case Delete:
+				out.WriteString("-")
+			}
+			out.WriteString(e.Text)
+			out.WriteString("\n")
+		}
+		i = j + 1
+	}
+	return out.String()
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// SplitWords splits text into words, runs of spaces and tabs, and line
+// breaks, so that joining the pieces gives back the text.
+func SplitWords(text string) []string {
+	var words []string
+	start := 0
+	for i, r := range text {
+		if i > start && (r == '\n' || text[i-1] == '\n' || isBlank(r) != isBlank(rune(text[i-1]))) {
+			words = append(words, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// Words returns the edit script turning one text into another word by word.
+// The texts are first compared line by line, and only the lines that changed
+// are compared word by word, so unrelated edits are not interleaved. Adjacent
+// edits of the same kind are merged, and every line ends in a newline.
+func Words(from, to string) []Edit {
+	var edits []Edit
+	add := func(op Op, text string) {
+		if n := len(edits); n > 0 && edits[n-1].Op == op {
+			edits[n-1].Text += text
+			return
+		}
+		edits = append(edits, Edit{op, text})
+	}
+
+	lines := Diff(SplitLines(from), SplitLines(to))
+	for i := 0; i < len(lines); {
+		if lines[i].Op == Equal {
+			add(Equal, lines[i].Text+"\n")
+			i++
+			continue
+		}
+		var removed, added strings.Builder
+		for ; i < len(lines) && lines[i].Op != Equal; i++ {
+			if lines[i].Op == Delete {
+				removed.WriteString(lines[i].Text + "\n")
+			} else {
+				added.WriteString(lines[i].Text + "\n")
+			}
+		}
+		for _, e := range Diff(SplitWords(removed.String()), SplitWords(added.String())) {
+			add(e.Op, e.Text)
+		}
+	}
+	return edits
+}
+
+// Lines splits an edit script into lines, without their line breaks, so that
+// each line can be printed on its own.
+func Lines(edits []Edit) [][]Edit {
+	var lines [][]Edit
+	var line []Edit
+	for _, e := range edits {
+		parts := strings.Split(e.Text, "\n")
+		for i, part := range parts {
+			if part != "" {
+				line = append(line, Edit{e.Op, part})
+			}
+			if i < len(parts)-1 {
+				lines = append(lines, line)
+				line = nil
+			}
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
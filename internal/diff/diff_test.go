@@ -0,0 +1,315 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sides returns the two sequences an edit script was computed from.
+func sides(edits []Edit) (a, b []string) {
+	for _, e := range edits {
+		if e.Op != Insert {
+			a = append(a, e.Text)
+		}
+		if e.Op != Delete {
+			b = append(b, e.Text)
+		}
+	}
+	return a, b
+}
+
+func distance(edits []Edit) int {
+	n := 0
+	for _, e := range edits {
+		if e.Op != Equal {
+			n++
+		}
+	}
+	return n
+}
+
+// shortestDistance computes the length of the shortest edit script from the
+// longest common subsequence.
+func shortestDistance(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+func checkScript(t *testing.T, a, b []string, edits []Edit) {
+	t.Helper()
+	gotA, gotB := sides(edits)
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+		t.Fatalf("Diff(%q, %q) = %v, which turns %q into %q", a, b, edits, gotA, gotB)
+	}
+	if d, want := distance(edits), shortestDistance(a, b); d != want {
+		t.Fatalf("Diff(%q, %q) = %v with %d edits, want %d", a, b, edits, d, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Edit
+	}{
+		{name: "both empty", a: "", b: "", want: nil},
+		{name: "identical", a: "a b c", b: "a b c", want: []Edit{{Equal, "a"}, {Equal, "b"}, {Equal, "c"}}},
+		{name: "all inserted", a: "", b: "a b", want: []Edit{{Insert, "a"}, {Insert, "b"}}},
+		{name: "all deleted", a: "a b", b: "", want: []Edit{{Delete, "a"}, {Delete, "b"}}},
+		{name: "insert in middle", a: "a c", b: "a b c", want: []Edit{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}}},
+		{name: "delete in middle", a: "a b c", b: "a c", want: []Edit{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}}},
+		{name: "replace", a: "a b c", b: "a x c", want: []Edit{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+			got := Diff(a, b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff(%q, %q) = %v, want %v", a, b, got, tt.want)
+			}
+			checkScript(t, a, b, got)
+		})
+	}
+}
+
+func TestDiffIsShortest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sequence := func() []string {
+		s := make([]string, r.Intn(15))
+		for i := range s {
+			s[i] = string(rune('a' + r.Intn(4)))
+		}
+		return s
+	}
+	for range 2000 {
+		a, b := sequence(), sequence()
+		checkScript(t, a, b, Diff(a, b))
+	}
+}
+
+func TestDiffBeyondMaxDistance(t *testing.T) {
+	// Unrelated texts whose shared first and last lines are kept, and whose
+	// middles differ by more than maxDistance, are replaced as a block.
+	a := []string{"first"}
+	b := []string{"first"}
+	for i := range maxDistance {
+		a = append(a, fmt.Sprint("a", i))
+		b = append(b, fmt.Sprint("b", i))
+	}
+	a = append(a, "last")
+	b = append(b, "last")
+
+	got := Diff(a, b)
+	gotA, gotB := sides(got)
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+		t.Fatalf("Diff does not turn a into b")
+	}
+	want := []Edit{{Equal, "first"}}
+	for _, s := range a[1 : len(a)-1] {
+		want = append(want, Edit{Delete, s})
+	}
+	for _, s := range b[1 : len(b)-1] {
+		want = append(want, Edit{Insert, s})
+	}
+	want = append(want, Edit{Equal, "last"})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff of unrelated texts is not a block replacement")
+	}
+
+	// Texts that differ by less than maxDistance still get the shortest script.
+	c := slices.Clone(a)
+	for i := 1; i < len(c)-1; i += 3 {
+		c[i] = "changed"
+	}
+	checkScript(t, a, c, Diff(a, c))
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "\n", want: []string{""}},
+		{text: "a", want: []string{"a"}},
+		{text: "a\n", want: []string{"a"}},
+		{text: "a\n\nb", want: []string{"a", "", "b"}},
+		{text: "a\nb\n\n", want: []string{"a", "b", ""}},
+	}
+	for _, tt := range tests {
+		if got := SplitLines(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	letters := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	twoChanges := "a\nB\nc\nd\ne\nf\ng\nh\nI\nj\n"
+	tests := []struct {
+		name     string
+		from, to string
+		context  int
+		want     string
+	}{
+		{name: "identical", from: letters, to: letters, context: 3, want: ""},
+		{name: "both empty", from: "", to: "", context: 3, want: ""},
+		{
+			name: "single line", from: "x\n", to: "y\n", context: 3,
+			want: "--- from\n+++ to\n@@ -1 +1 @@\n-x\n+y\n",
+		},
+		{
+			name: "into empty file", from: "", to: "x\ny\n", context: 3,
+			want: "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "to empty file", from: "x\ny\n", to: "", context: 3,
+			want: "--- from\n+++ to\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name: "context is clipped at the start", from: letters, to: "A\n" + letters[2:], context: 2,
+			want: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n-a\n+A\n b\n c\n",
+		},
+		{
+			name: "context is clipped at the end", from: letters, to: letters[:18] + "J\n", context: 2,
+			want: "--- from\n+++ to\n@@ -8,3 +8,3 @@\n h\n i\n-j\n+J\n",
+		},
+		{
+			name: "insertion", from: "a\nb\nc\nd\n", to: "a\nb\nx\nc\nd\n", context: 1,
+			want: "--- from\n+++ to\n@@ -2,2 +2,3 @@\n b\n+x\n c\n",
+		},
+		{
+			name: "separate hunks", from: letters, to: twoChanges, context: 1,
+			want: "--- from\n+++ to\n" +
+				"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n" +
+				"@@ -8,3 +8,3 @@\n h\n-i\n+I\n j\n",
+		},
+		{
+			name: "hunks just apart", from: letters, to: twoChanges, context: 3,
+			want: "--- from\n+++ to\n" +
+				"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -6,5 +6,5 @@\n f\n g\n h\n-i\n+I\n j\n",
+		},
+		{
+			name: "overlapping hunks are merged", from: letters, to: twoChanges, context: 4,
+			want: "--- from\n+++ to\n" +
+				"@@ -1,10 +1,10 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n h\n-i\n+I\n j\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("from", "to", tt.from, tt.to, tt.context); got != tt.want {
+				t.Errorf("Unified(%q, %q, %d) =\n%s\nwant\n%s", tt.from, tt.to, tt.context, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "one", want: []string{"one"}},
+		{text: "one two", want: []string{"one", " ", "two"}},
+		{text: "one  \ttwo\n", want: []string{"one", "  \t", "two", "\n"}},
+		{text: "a\n\nb", want: []string{"a", "\n", "\n", "b"}},
+		{text: " lead trail ", want: []string{" ", "lead", " ", "trail", " "}},
+		{text: "héllo wörld", want: []string{"héllo", " ", "wörld"}},
+	}
+	for _, tt := range tests {
+		got := SplitWords(tt.text)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SplitWords(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if joined := strings.Join(got, ""); joined != tt.text {
+			t.Errorf("SplitWords(%q) joins back to %q", tt.text, joined)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []Edit
+	}{
+		{name: "identical", from: "a b\nc\n", to: "a b\nc\n", want: []Edit{{Equal, "a b\nc\n"}}},
+		{
+			name: "one word", from: "the quick fox\nsame\n", to: "the slow fox\nsame\n",
+			want: []Edit{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " fox\nsame\n"}},
+		},
+		{
+			name: "added line", from: "a\nc\n", to: "a\nb\nc\n",
+			want: []Edit{{Equal, "a\n"}, {Insert, "b\n"}, {Equal, "c\n"}},
+		},
+		{
+			name: "unrelated edits stay on their lines", from: "one two\nkeep\nthree four\n", to: "one 2\nkeep\n3 four\n",
+			want: []Edit{
+				{Equal, "one "}, {Delete, "two"}, {Insert, "2"}, {Equal, "\nkeep\n"},
+				{Delete, "three"}, {Insert, "3"}, {Equal, " four\n"},
+			},
+		},
+		{
+			name: "missing final newline", from: "a b", to: "a c",
+			want: []Edit{{Equal, "a "}, {Delete, "b"}, {Insert, "c"}, {Equal, "\n"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Words(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %q, want %q", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	edits := []Edit{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " fox\n\nend"}}
+	want := [][]Edit{
+		{{Equal, "the "}, {Delete, "quick"}, {Insert, "slow"}, {Equal, " fox"}},
+		nil,
+		{{Equal, "end"}},
+	}
+	if got := Lines(edits); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines(%q) = %q, want %q", edits, got, want)
+	}
+
+	if got := Lines(Words("a\n", "a\nb\n")); !reflect.DeepEqual(got, [][]Edit{{{Equal, "a"}}, {{Insert, "b"}}}) {
+		t.Errorf("Lines of an added line = %q", got)
+	}
+	if got := Lines(nil); got != nil {
+		t.Errorf("Lines(nil) = %q, want nil", got)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Table is a grid of text with a header row.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// CellChange is a cell whose value differs between two tables.
+type CellChange struct {
+	Column string `json:"column"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// RowChange is a row that was added, removed or changed, identified by its key.
+// Row is the 1-based position of the row below the header in the table it was
+// found in: the new table for added and changed rows, the old one for removed rows.
+type RowChange struct {
+	Key    string            `json:"key"`
+	Row    int               `json:"row"`
+	Values map[string]string `json:"values,omitempty"`
+	Cells  []CellChange      `json:"cells,omitempty"`
+}
+
+// TableDiff is the difference between two tables whose rows are matched by a key column.
+type TableDiff struct {
+	Key            string       `json:"key"`
+	AddedColumns   []string     `json:"addedColumns"`
+	RemovedColumns []string     `json:"removedColumns"`
+	Added          []*RowChange `json:"added"`
+	Removed        []*RowChange `json:"removed"`
+	Changed        []*RowChange `json:"changed"`
+}
+
+// Empty reports whether the tables were identical.
+func (d *TableDiff) Empty() bool {
+	return len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 &&
+		len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// CompareTables matches the rows of two tables by the value in the key column
+// and reports the rows and cells that differ. Rows with an empty key are
+// ignored, and columns are matched by header name.
+func CompareTables(from, to *Table, key string) (*TableDiff, error) {
+	fromIndex, err := keyIndex(from, key, "old")
+	if err != nil {
+		return nil, err
+	}
+	toIndex, err := keyIndex(to, key, "new")
+	if err != nil {
+		return nil, err
+	}
+
+	d := &TableDiff{
+		Key:            key,
+		AddedColumns:   []string{},
+		RemovedColumns: []string{},
+		Added:          []*RowChange{},
+		Removed:        []*RowChange{},
+		Changed:        []*RowChange{},
+	}
+	for _, h := range to.Header {
+		if h != "" && !slices.Contains(from.Header, h) {
+			d.AddedColumns = append(d.AddedColumns, h)
+		}
+	}
+	for _, h := range from.Header {
+		if h != "" && !slices.Contains(to.Header, h) {
+			d.RemovedColumns = append(d.RemovedColumns, h)
+		}
+	}
+
+	for i, row := range to.Rows {
+		k := cell(row, toIndex.column)
+		if k == "" {
+			continue
+		}
+		j, ok := fromIndex.rows[k]
+		if !ok {
+			d.Added = append(d.Added, &RowChange{Key: k, Row: i + 1, Values: rowValues(to, row)})
+			continue
+		}
+		// Compare every named column present in either table.
+		var cells []CellChange
+		for _, col := range unionColumns(from.Header, to.Header) {
+			a := cell(from.Rows[j], slices.Index(from.Header, col))
+			b := cell(row, slices.Index(to.Header, col))
+			if a != b {
+				cells = append(cells, CellChange{Column: col, From: a, To: b})
+			}
+		}
+		if len(cells) > 0 {
+			d.Changed = append(d.Changed, &RowChange{Key: k, Row: i + 1, Cells: cells})
+		}
+	}
+	for i, row := range from.Rows {
+		k := cell(row, fromIndex.column)
+		if k == "" {
+			continue
+		}
+		if _, ok := toIndex.rows[k]; !ok {
+			d.Removed = append(d.Removed, &RowChange{Key: k, Row: i + 1, Values: rowValues(from, row)})
+		}
+	}
+	return d, nil
+}
+
+type tableIndex struct {
+	column int
+	rows   map[string]int
+}
+
+func keyIndex(t *Table, key string, name string) (*tableIndex, error) {
+	column := slices.Index(t.Header, key)
+	if column < 0 {
+		return nil, fmt.Errorf("key column %q not found in the %s table (columns: %s)", key, name, strings.Join(t.Header, ", "))
+	}
+	idx := &tableIndex{column: column, rows: make(map[string]int)}
+	for i, row := range t.Rows {
+		k := cell(row, column)
+		if k == "" {
+			continue
+		}
+		if prev, dup := idx.rows[k]; dup {
+			return nil, fmt.Errorf("duplicate key %q in the %s table (rows %d and %d)", k, name, prev+1, i+1)
+		}
+		idx.rows[k] = i
+	}
+	return idx, nil
+}
+
+func unionColumns(a, b []string) []string {
+	var cols []string
+	for _, h := range append(slices.Clone(a), b...) {
+		if h != "" && !slices.Contains(cols, h) {
+			cols = append(cols, h)
+		}
+	}
+	return cols
+}
+
+func rowValues(t *Table, row []string) map[string]string {
+	values := make(map[string]string)
+	for i, h := range t.Header {
+		if h != "" {
+			values[h] = cell(row, i)
+		}
+	}
+	return values
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// PatchOp is one JSON Patch (RFC 6902) operation.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Patch expresses the difference as a JSON Patch over an object that maps
+// each key to a row object. Every replace is preceded by a test of the old
+// value, so the patch fails cleanly if applied to a different version.
+func (d *TableDiff) Patch() []PatchOp {
+	ops := []PatchOp{}
+	for _, r := range d.Removed {
+		ops = append(ops, PatchOp{Op: "remove", Path: "/" + patchToken(r.Key)})
+	}
+	for _, r := range d.Added {
+		ops = append(ops, PatchOp{Op: "add", Path: "/" + patchToken(r.Key), Value: r.Values})
+	}
+	for _, r := range d.Changed {
+		for _, c := range r.Cells {
+			path := "/" + patchToken(r.Key) + "/" + patchToken(c.Column)
+			switch {
+			case slices.Contains(d.AddedColumns, c.Column):
+				ops = append(ops, PatchOp{Op: "add", Path: path, Value: c.To})
+			case slices.Contains(d.RemovedColumns, c.Column):
+				ops = append(ops,
+					PatchOp{Op: "test", Path: path, Value: c.From},
+					PatchOp{Op: "remove", Path: path},
+				)
+			default:
+				ops = append(ops,
+					PatchOp{Op: "test", Path: path, Value: c.From},
+					PatchOp{Op: "replace", Path: path, Value: c.To},
+				)
+			}
+		}
+	}
+	return ops
+}
+
+// patchToken escapes a JSON Pointer reference token.
+func patchToken(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// Sharing audit issue types.
+const (
+	IssuePublic         = "public"
+	IssuePublicLink     = "public-link"
+	IssueExternalDomain = "external-domain"
+	IssueExternalUser   = "external-user"
+	IssueDeletedUser    = "deleted-user"
+	IssueDeletedOwner   = "deleted-owner"
+)
+
+// SharingFinding is a single risky permission found during a sharing audit.
+type SharingFinding struct {
+	FileID   string `json:"fileId"`
+	Path     string `json:"path"`
+	MimeType string `json:"mimeType"`
+	Issue    string `json:"issue"`
+	Type     string `json:"type"`
+	Target   string `json:"target"`
+	Role     string `json:"role"`
+	Link     string `json:"link,omitempty"`
+}
+
+// SharingAudit is the result of auditing a folder tree.
+type SharingAudit struct {
+	FolderID        string            `json:"folderId"`
+	InternalDomains []string          `json:"internalDomains"`
+	FilesScanned    int               `json:"filesScanned"`
+	Findings        []*SharingFinding `json:"findings"`
+}
+
+// CurrentUserEmail returns the email address of the authenticated user.
+func CurrentUserEmail(srv *drive.Service) (string, error) {
+	about, err := srv.About.Get().Fields("user(emailAddress)").Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve current user: %w", err)
+	}
+	if about.User == nil {
+		return "", fmt.Errorf("unable to retrieve current user")
+	}
+	return about.User.EmailAddress, nil
+}
+
+// AuditSharing walks a folder tree and reports permissions that grant access
+// outside internalDomains, via public links, or to deleted accounts.
+func AuditSharing(srv *drive.Service, folderID string, internalDomains []string) (*SharingAudit, error) {
+	audit := &SharingAudit{
+		FolderID:        folderID,
+		InternalDomains: internalDomains,
+		Findings:        []*SharingFinding{},
+	}
+
+	err := WalkFolder(srv, folderID, func(file *drive.File, path string) error {
+		// The permissions field of a file is empty for items in shared drives,
+		// so they are listed separately.
+		perms, err := ListPermissions(srv, file.Id)
+		if err != nil {
+			return err
+		}
+		audit.FilesScanned++
+		for _, p := range perms {
+			issue := classifyPermission(p, internalDomains)
+			if issue == "" {
+				continue
+			}
+			audit.Findings = append(audit.Findings, &SharingFinding{
+				FileID:   file.Id,
+				Path:     path,
+				MimeType: file.MimeType,
+				Issue:    issue,
+				Type:     p.Type,
+				Target:   PermissionTarget(p),
+				Role:     p.Role,
+				Link:     file.WebViewLink,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audit, nil
+}
+
+// classifyPermission returns the audit issue raised by a permission, or "" if it is not a concern.
+// Drive only marks permissions of deleted accounts; suspended accounts look like any other.
+func classifyPermission(p *drive.Permission, internalDomains []string) string {
+	if p.Deleted {
+		if p.Role == "owner" {
+			return IssueDeletedOwner
+		}
+		return IssueDeletedUser
+	}
+
+	switch p.Type {
+	case "anyone":
+		if p.AllowFileDiscovery {
+			return IssuePublic
+		}
+		return IssuePublicLink
+	case "domain":
+		if !isInternalDomain(p.Domain, internalDomains) {
+			return IssueExternalDomain
+		}
+	case "user", "group":
+		_, domain, _ := strings.Cut(p.EmailAddress, "@")
+		if !isInternalDomain(domain, internalDomains) {
+			return IssueExternalUser
+		}
+	}
+	return ""
+}
+
+func isInternalDomain(domain string, internalDomains []string) bool {
+	if len(internalDomains) == 0 {
+		return true
+	}
+	for _, d := range internalDomains {
+		if strings.EqualFold(domain, d) {
+			return true
+		}
+	}
+	return false
+}
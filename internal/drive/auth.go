@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/pkg/browser"
 	"golang.org/x/oauth2"
@@ -44,7 +46,7 @@ func NewOAuthClient(ctx context.Context, secretFile string, noBrowserAuth bool)
 		return nil, fmt.Errorf("unable to read client secret file at %s: %v", secretFile, err)
 	}
 
-	config, err := google.ConfigFromJSON(b, drive.DriveReadonlyScope, docs.DocumentsReadonlyScope, sheets.SpreadsheetsScope, drive.DriveFileScope)
+	config, err := google.ConfigFromJSON(b, drive.DriveScope, docs.DocumentsReadonlyScope, sheets.SpreadsheetsScope)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
 	}
@@ -59,7 +61,13 @@ func GetClient(ctx context.Context, config *oauth2.Config, noBrowserAuth bool) (
 		return nil, fmt.Errorf("unable to get path to cached credential file: %v", err)
 	}
 
-	tok, err := TokenFromFile(cacheFile)
+	tok, scopes, err := TokenFromFile(cacheFile)
+	if err == nil && !hasScopes(scopes, config.Scopes) {
+		// Logins made before drivectl could change files were only granted
+		// read-only access, and would fail on every write with a 403.
+		fmt.Println("The saved login does not grant every permission drivectl needs, such as editing files. Please sign in again.")
+		err = fmt.Errorf("saved token lacks required scopes")
+	}
 	if err != nil {
 		if noBrowserAuth {
 			tok, err = GetTokenFromWeb(config)
@@ -69,7 +77,11 @@ func GetClient(ctx context.Context, config *oauth2.Config, noBrowserAuth bool) (
 		if err != nil {
 			return nil, err
 		}
-		if err := SaveToken(cacheFile, tok); err != nil {
+		granted := config.Scopes
+		if scope, ok := tok.Extra("scope").(string); ok && scope != "" {
+			granted = strings.Fields(scope)
+		}
+		if err := SaveToken(cacheFile, tok, granted); err != nil {
 			return nil, err
 		}
 	}
@@ -140,25 +152,42 @@ func TokenCacheFile() (string, error) {
 	return filepath.Join(configDir, "token.json"), nil
 }
 
-// TokenFromFile retrieves a token from a file.
-func TokenFromFile(file string) (*oauth2.Token, error) {
+// cachedToken is a token as saved in the token cache file, along with the
+// scopes it was granted. Tokens saved by earlier versions have no scopes.
+type cachedToken struct {
+	*oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// TokenFromFile retrieves a token and the scopes it was granted from a file.
+func TokenFromFile(file string) (*oauth2.Token, []string, error) {
 	f, err := os.Open(file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer f.Close()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
+	cached := cachedToken{Token: &oauth2.Token{}}
+	err = json.NewDecoder(f).Decode(&cached)
+	return cached.Token, cached.Scopes, err
+}
+
+// hasScopes reports whether granted includes every scope in required.
+func hasScopes(granted, required []string) bool {
+	for _, s := range required {
+		if !slices.Contains(granted, s) {
+			return false
+		}
+	}
+	return true
 }
 
-// SaveToken saves a token to a file.
-func SaveToken(file string, token *oauth2.Token) error {
+// SaveToken saves a token and the scopes it was granted to a file.
+func SaveToken(file string, token *oauth2.Token, scopes []string) error {
 	fmt.Printf("Saving credential file to: %s\n", file)
 	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return fmt.Errorf("unable to cache oauth token: %v", err)
 	}
 	defer f.Close()
-	return json.NewEncoder(f).Encode(token)
+	return json.NewEncoder(f).Encode(cachedToken{Token: token, Scopes: scopes})
 }
@@ -0,0 +1,232 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ChangeEvent is a simplified view of a single Drive change.
+type ChangeEvent struct {
+	Type     string `json:"type"`
+	FileID   string `json:"fileId"`
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Time     string `json:"time"`
+}
+
+// ChangeFilter restricts which changes are reported.
+type ChangeFilter struct {
+	// FolderID only keeps files whose direct parent is this folder.
+	FolderID string
+	// MimeType only keeps files of this MIME type.
+	MimeType string
+}
+
+// ChangeState is the persisted position in the change log for a profile.
+type ChangeState struct {
+	PageToken string `json:"pageToken"`
+	LastRun   string `json:"lastRun"`
+	// Files holds the files last seen to match the filter, so that their
+	// removal can still be reported when it arrives without metadata.
+	Files map[string]*KnownFile `json:"files,omitempty"`
+}
+
+// KnownFile is the last-known name, type and location of a file.
+type KnownFile struct {
+	Name     string   `json:"name,omitempty"`
+	MimeType string   `json:"mimeType,omitempty"`
+	Parents  []string `json:"parents,omitempty"`
+}
+
+// Remember records files that match filter in the state, such as the
+// contents of a watched folder when the state is first created.
+func (s *ChangeState) Remember(files []*drive.File) {
+	if s.Files == nil {
+		s.Files = make(map[string]*KnownFile)
+	}
+	for _, f := range files {
+		s.Files[f.Id] = &KnownFile{Name: f.Name, MimeType: f.MimeType, Parents: f.Parents}
+	}
+}
+
+// GetStartPageToken returns the token marking the current head of the change log.
+func GetStartPageToken(srv *drive.Service) (string, error) {
+	call := srv.Changes.GetStartPageToken().SupportsAllDrives(true)
+	if scope.DriveID != "" {
+		call = call.DriveId(scope.DriveID)
+	}
+	resp, err := call.Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to get start page token: %w", err)
+	}
+	return resp.StartPageToken, nil
+}
+
+// ListChanges retrieves every change since pageToken, following pagination.
+// It returns the changes and the token to use for the next call.
+func ListChanges(srv *drive.Service, pageToken string) ([]*drive.Change, string, error) {
+	var changes []*drive.Change
+	for pageToken != "" {
+		call := srv.Changes.List(pageToken).PageSize(1000).IncludeRemoved(true).SupportsAllDrives(true)
+		switch {
+		case scope.DriveID != "":
+			call = call.DriveId(scope.DriveID).IncludeItemsFromAllDrives(true)
+		case scope.AllDrives:
+			call = call.IncludeItemsFromAllDrives(true)
+		}
+		resp, err := call.
+			Fields("nextPageToken, newStartPageToken, changes(changeType, fileId, removed, time, file(id, name, mimeType, parents, trashed, createdTime))").Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to list changes: %w", err)
+		}
+		changes = append(changes, resp.Changes...)
+		if resp.NewStartPageToken != "" {
+			return changes, resp.NewStartPageToken, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+	return changes, "", fmt.Errorf("change list ended without a new start page token")
+}
+
+// ClassifyChanges converts raw changes into added, modified and removed events.
+// A file created after since is reported as added rather than modified.
+// When filter is set, the files that match it are tracked in state, so that a
+// removal, which carries no file metadata, is matched against where the file
+// was last seen.
+func ClassifyChanges(changes []*drive.Change, since time.Time, filter ChangeFilter, state *ChangeState) []*ChangeEvent {
+	filtered := filter.FolderID != "" || filter.MimeType != ""
+	var events []*ChangeEvent
+	for _, c := range changes {
+		if c.ChangeType != "" && c.ChangeType != "file" {
+			continue
+		}
+		file := state.Files[c.FileId]
+		if c.File != nil {
+			file = &KnownFile{Name: c.File.Name, MimeType: c.File.MimeType, Parents: c.File.Parents}
+		}
+		removed := c.Removed || (c.File != nil && c.File.Trashed)
+		if filtered {
+			matches := file != nil && matchesChangeFilter(file, filter)
+			if matches && !removed {
+				if state.Files == nil {
+					state.Files = make(map[string]*KnownFile)
+				}
+				state.Files[c.FileId] = file
+			} else {
+				delete(state.Files, c.FileId)
+			}
+			if !matches {
+				continue
+			}
+		}
+
+		event := &ChangeEvent{
+			Type:   "modified",
+			FileID: c.FileId,
+			Time:   c.Time,
+		}
+		if file != nil {
+			event.Name = file.Name
+			event.MimeType = file.MimeType
+		}
+
+		switch {
+		case removed:
+			event.Type = "removed"
+		case c.File != nil && c.File.CreatedTime != "":
+			if created, err := time.Parse(time.RFC3339, c.File.CreatedTime); err == nil && !since.IsZero() && created.After(since) {
+				event.Type = "added"
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func matchesChangeFilter(file *KnownFile, filter ChangeFilter) bool {
+	if filter.MimeType != "" && file.MimeType != filter.MimeType {
+		return false
+	}
+	if filter.FolderID != "" {
+		return slices.Contains(file.Parents, filter.FolderID)
+	}
+	return true
+}
+
+func changeStateFile() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "changes.json"), nil
+}
+
+func readChangeStates() (map[string]*ChangeState, error) {
+	file, err := changeStateFile()
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]*ChangeState)
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read change state: %w", err)
+	}
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, fmt.Errorf("unable to parse change state %s: %w", file, err)
+	}
+	return states, nil
+}
+
+// LoadChangeState returns the saved change state for a profile, or nil if none exists.
+func LoadChangeState(profile string) (*ChangeState, error) {
+	states, err := readChangeStates()
+	if err != nil {
+		return nil, err
+	}
+	return states[profile], nil
+}
+
+// SaveChangeState persists the change state for a profile.
+func SaveChangeState(profile string, state *ChangeState) error {
+	states, err := readChangeStates()
+	if err != nil {
+		return err
+	}
+	states[profile] = state
+
+	file, err := changeStateFile()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, b, 0600); err != nil {
+		return fmt.Errorf("unable to save change state: %w", err)
+	}
+	return nil
+}
@@ -16,16 +16,171 @@ package drive
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"google.golang.org/api/drive/v3"
 )
 
-// ListComments retrieves a list of comments for a given file ID.
-func ListComments(srv *drive.Service, fileId string) ([]*drive.Comment, error) {
-	call := srv.Comments.List(fileId).Fields("comments(id,content,author(displayName,emailAddress),createdTime,resolved,quotedFileContent(value),replies(id,content,author(displayName,emailAddress),createdTime,action))")
-	resp, err := call.Do()
+const (
+	replyFields   = "id,content,author(displayName,emailAddress),createdTime,modifiedTime,action"
+	commentFields = "id,content,author(displayName,emailAddress),createdTime,modifiedTime,resolved,quotedFileContent(value),replies(" + replyFields + ")"
+)
+
+// CommentFilter restricts which comments ListComments returns.
+type CommentFilter struct {
+	// Unresolved only keeps open comment threads.
+	Unresolved bool
+	// Author only keeps comments whose author's name or email contains this value.
+	Author string
+	// Since only keeps comments modified at or after this time.
+	Since time.Time
+}
+
+// ListComments retrieves the comments for a given file ID, following pagination.
+func ListComments(srv *drive.Service, fileId string, filter CommentFilter) ([]*drive.Comment, error) {
+	var comments []*drive.Comment
+	pageToken := ""
+	for {
+		call := srv.Comments.List(fileId).PageSize(100).PageToken(pageToken).
+			Fields("nextPageToken,comments(" + commentFields + ")")
+		if !filter.Since.IsZero() {
+			call = call.StartModifiedTime(filter.Since.UTC().Format(time.RFC3339))
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list comments: %w", err)
+		}
+		for _, c := range resp.Comments {
+			if matchesCommentFilter(c, filter) {
+				comments = append(comments, c)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return comments, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func matchesCommentFilter(c *drive.Comment, filter CommentFilter) bool {
+	if filter.Unresolved && c.Resolved {
+		return false
+	}
+	if filter.Author != "" {
+		if c.Author == nil {
+			return false
+		}
+		want := strings.ToLower(filter.Author)
+		if !strings.Contains(strings.ToLower(c.Author.DisplayName), want) &&
+			!strings.Contains(strings.ToLower(c.Author.EmailAddress), want) {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateComment posts a new comment on a file. If quote is set, the comment is
+// shown against that quoted text from the file.
+func CreateComment(srv *drive.Service, fileId string, content string, quote string) (*drive.Comment, error) {
+	comment := &drive.Comment{Content: content}
+	if quote != "" {
+		comment.QuotedFileContent = &drive.CommentQuotedFileContent{
+			MimeType: "text/plain",
+			Value:    quote,
+		}
+	}
+	res, err := srv.Comments.Create(fileId, comment).Fields(commentFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create comment: %w", err)
+	}
+	return res, nil
+}
+
+// ReplyToComment adds a reply to a comment thread. action may be "resolve" or
+// "reopen" to change the state of the thread, or empty for a plain reply.
+func ReplyToComment(srv *drive.Service, fileId string, commentId string, content string, action string) (*drive.Reply, error) {
+	reply := &drive.Reply{
+		Content: content,
+		Action:  action,
+	}
+	res, err := srv.Replies.Create(fileId, commentId, reply).Fields(replyFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to reply to comment %s: %w", commentId, err)
+	}
+	return res, nil
+}
+
+// DeleteComment deletes a comment thread, or a single reply within it when replyId is set.
+func DeleteComment(srv *drive.Service, fileId string, commentId string, replyId string) error {
+	var err error
+	if replyId != "" {
+		err = srv.Replies.Delete(fileId, commentId, replyId).Do()
+	} else {
+		err = srv.Comments.Delete(fileId, commentId).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete comment %s: %w", commentId, err)
+	}
+	return nil
+}
+
+// DocumentComments is the set of comment threads on a single file.
+type DocumentComments struct {
+	FileID   string           `json:"fileId"`
+	Name     string           `json:"name"`
+	Path     string           `json:"path"`
+	Link     string           `json:"link,omitempty"`
+	Comments []*drive.Comment `json:"comments"`
+}
+
+// CollectComments gathers comment threads for a report. For a file, it returns
+// that file's comments. For a folder, it walks the folder tree and returns the
+// unresolved comments of every Google Doc inside it, skipping Docs without any.
+func CollectComments(srv *drive.Service, fileId string, filter CommentFilter) ([]*DocumentComments, error) {
+	file, err := srv.Files.Get(fileId).SupportsAllDrives(true).Fields("id, name, mimeType, webViewLink").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve file %s: %w", fileId, err)
+	}
+
+	if file.MimeType != FolderMimeType {
+		comments, err := ListComments(srv, fileId, filter)
+		if err != nil {
+			return nil, err
+		}
+		return []*DocumentComments{{
+			FileID:   file.Id,
+			Name:     file.Name,
+			Path:     file.Name,
+			Link:     file.WebViewLink,
+			Comments: comments,
+		}}, nil
+	}
+
+	filter.Unresolved = true
+	docs := []*DocumentComments{}
+	err = WalkFolder(srv, fileId, func(f *drive.File, path string) error {
+		if f.MimeType != "application/vnd.google-apps.document" {
+			return nil
+		}
+		comments, err := ListComments(srv, f.Id, filter)
+		if err != nil {
+			return err
+		}
+		if len(comments) == 0 {
+			return nil
+		}
+		docs = append(docs, &DocumentComments{
+			FileID:   f.Id,
+			Name:     f.Name,
+			Path:     path,
+			Link:     f.WebViewLink,
+			Comments: comments,
+		})
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to list comments: %w", err)
+		return nil, err
 	}
-	return resp.Comments, nil
+	return docs, nil
 }
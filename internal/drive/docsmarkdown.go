@@ -0,0 +1,175 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// GetDocumentMarkdown renders a Google Doc, or one of its tabs, as Markdown.
+func GetDocumentMarkdown(docsSvc *docs.Service, documentId string, tabId string) ([]byte, error) {
+	return getDocumentTabContent(docsSvc, documentId, tabId, "")
+}
+
+// suggestionsViewModes maps each way of showing pending suggestions to the
+// Docs API view mode that fetches it.
+var suggestionsViewModes = map[string]string{
+	"accept": "PREVIEW_SUGGESTIONS_ACCEPTED",
+	"reject": "PREVIEW_WITHOUT_SUGGESTIONS",
+	"inline": "SUGGESTIONS_INLINE",
+}
+
+// markSuggestion wraps the text of a suggested insertion in {++ ++} and of a
+// suggested deletion in {-- --}. The Docs API does not expose who made a
+// suggestion, so no {>> <<} comment naming the author follows it. Trailing
+// spaces and line breaks are left outside.
+func markSuggestion(content string, run *docs.TextRun) string {
+	var open, close string
+	switch {
+	case len(run.SuggestedInsertionIds) > 0:
+		open, close = "{++", "++}"
+	case len(run.SuggestedDeletionIds) > 0:
+		open, close = "{--", "--}"
+	default:
+		return content
+	}
+	text := strings.TrimRight(content, " \n")
+	if text == "" {
+		return content
+	}
+	return open + text + close + content[len(text):]
+}
+
+// NormalizeMarkdown renders Markdown the way GetDocumentMarkdown would render
+// a Google Doc created from it with CreateDocFromMarkdown, so that a local
+// file can be compared with a document. The document is built locally from
+// the same requests, without calling the Docs API.
+func NormalizeMarkdown(markdown string) ([]byte, error) {
+	requests, err := MarkdownToDocsRequests(markdown)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert markdown to requests: %w", err)
+	}
+	body, err := applyDocsRequests(requests)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(renderBodyAsText(body)), nil
+}
+
+// charStyle is the style of one character of a locally built document.
+type charStyle struct {
+	bold, italic bool
+	link         string
+	heading      string
+	bullet       bool
+}
+
+// applyDocsRequests builds the body of a new, empty document after the
+// requests MarkdownToDocsRequests produces have been applied to it. Indexes
+// count bytes, as the converter does.
+func applyDocsRequests(requests []*docs.Request) (*docs.Body, error) {
+	// A new document holds a single newline at index 1.
+	text := []byte("\n")
+	styles := []charStyle{{}}
+	span := func(r *docs.Range) (int, int, error) {
+		start, end := int(r.StartIndex)-1, int(r.EndIndex)-1
+		if start < 0 || end > len(text) || start > end {
+			return 0, 0, fmt.Errorf("range %d-%d is outside the document", r.StartIndex, r.EndIndex)
+		}
+		return start, end, nil
+	}
+
+	for _, req := range requests {
+		switch {
+		case req.InsertText != nil:
+			at := int(req.InsertText.Location.Index) - 1
+			if at < 0 || at > len(text) {
+				return nil, fmt.Errorf("index %d is outside the document", req.InsertText.Location.Index)
+			}
+			// Inserted text is plain until a later request styles it.
+			text = slices.Insert(text, at, []byte(req.InsertText.Text)...)
+			styles = slices.Insert(styles, at, make([]charStyle, len(req.InsertText.Text))...)
+		case req.UpdateTextStyle != nil:
+			start, end, err := span(req.UpdateTextStyle.Range)
+			if err != nil {
+				return nil, err
+			}
+			ts := req.UpdateTextStyle.TextStyle
+			for i := start; i < end; i++ {
+				switch req.UpdateTextStyle.Fields {
+				case "*":
+					styles[i].bold, styles[i].italic, styles[i].link = ts.Bold, ts.Italic, ""
+					if ts.Link != nil {
+						styles[i].link = ts.Link.Url
+					}
+				case "link":
+					styles[i].link = ""
+					if ts.Link != nil {
+						styles[i].link = ts.Link.Url
+					}
+				}
+			}
+		case req.UpdateParagraphStyle != nil:
+			start, end, err := span(req.UpdateParagraphStyle.Range)
+			if err != nil {
+				return nil, err
+			}
+			for i := start; i < end; i++ {
+				styles[i].heading = req.UpdateParagraphStyle.ParagraphStyle.NamedStyleType
+			}
+		case req.CreateParagraphBullets != nil:
+			start, end, err := span(req.CreateParagraphBullets.Range)
+			if err != nil {
+				return nil, err
+			}
+			for i := start; i < end; i++ {
+				styles[i].bullet = true
+			}
+		}
+	}
+
+	// Split the text into paragraphs, and each paragraph into runs of text
+	// that share a style. A paragraph takes its style from its first character.
+	body := &docs.Body{}
+	for start := 0; start < len(text); {
+		end := start + strings.IndexByte(string(text[start:]), '\n') + 1
+		p := &docs.Paragraph{ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "NORMAL_TEXT"}}
+		if h := styles[start].heading; h != "" {
+			p.ParagraphStyle.NamedStyleType = h
+		}
+		if styles[start].bullet {
+			p.Bullet = &docs.Bullet{}
+		}
+		for i := start; i < end; {
+			j := i + 1
+			for j < end && styles[j].bold == styles[i].bold && styles[j].italic == styles[i].italic && styles[j].link == styles[i].link {
+				j++
+			}
+			run := &docs.TextRun{Content: string(text[i:j]), TextStyle: &docs.TextStyle{Bold: styles[i].bold, Italic: styles[i].italic}}
+			if styles[i].link != "" {
+				run.TextStyle.Link = &docs.Link{Url: styles[i].link}
+			}
+			p.Elements = append(p.Elements, &docs.ParagraphElement{StartIndex: int64(i + 1), EndIndex: int64(j + 1), TextRun: run})
+			i = j
+		}
+		body.Content = append(body.Content, &docs.StructuralElement{StartIndex: int64(start + 1), EndIndex: int64(end + 1), Paragraph: p})
+		start = end
+	}
+	return body, nil
+}
@@ -0,0 +1,356 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ghchinoy/drivectl/internal/a1"
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/sheets/v4"
+)
+
+// DocumentMimeType is the MIME type Google Drive uses for Google Docs.
+const DocumentMimeType = "application/vnd.google-apps.document"
+
+// placeholderPattern matches a {{column}} placeholder.
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// MergeOptions controls how MergeDocs generates documents.
+type MergeOptions struct {
+	// Name is the pattern each copy is named from, with {{column}}
+	// placeholders. Defaults to the template name followed by the first column.
+	Name string
+	// Folder is the ID of the folder the copies are filed in. Defaults to the
+	// template's folder.
+	Folder string
+	// PDFDir, when set, is a local directory each copy is exported to as PDF.
+	PDFDir string
+	// IDColumn, when set, is the header of the column the new document IDs are
+	// written back to. The column is added when the sheet lacks it, and rows
+	// that already have an ID in it are skipped.
+	IDColumn string
+	// Read controls how the sheet values are rendered before substitution.
+	Read ValueRenderOptions
+	// DryRun reports the documents that would be created without creating them.
+	DryRun bool
+	// Progress, when set, is called after each document is generated.
+	Progress func(doc *MergedDoc)
+}
+
+// MergedDoc describes a document generated from one row of a sheet.
+type MergedDoc struct {
+	Row          int    `json:"row"`
+	Name         string `json:"name"`
+	DocumentID   string `json:"documentId,omitempty"`
+	WebViewLink  string `json:"webViewLink,omitempty"`
+	Replacements int64  `json:"replacements"`
+	PDF          string `json:"pdf,omitempty"`
+}
+
+// MergeResult describes the outcome of MergeDocs.
+type MergeResult struct {
+	TemplateID    string       `json:"templateId"`
+	SpreadsheetID string       `json:"spreadsheetId"`
+	Sheet         string       `json:"sheet"`
+	IDColumn      string       `json:"idColumn,omitempty"`
+	Documents     []*MergedDoc `json:"documents"`
+	// Skipped lists the rows that already had a document ID.
+	Skipped []int `json:"skipped,omitempty"`
+	// Unmatched lists the placeholders in the template that no column fills.
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// MergeDocs copies a template Google Doc once for every row of a sheet, and
+// replaces each {{column}} placeholder in the copy with the row's value for
+// that column, using the header row for column names. Blank rows are skipped.
+//
+// If a row fails, the IDs of the documents generated so far are still written
+// back, and the partial result is returned with the error.
+func MergeDocs(driveSvc *drive.Service, docsSvc *docs.Service, sheetsSvc *sheets.Service, templateId string, spreadsheetId string, sheetName string, opts MergeOptions) (*MergeResult, error) {
+	template, err := driveSvc.Files.Get(templateId).SupportsAllDrives(true).Fields(fileOpFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve template %s: %w", templateId, err)
+	}
+	if template.MimeType != DocumentMimeType {
+		return nil, fmt.Errorf("template %s is not a Google Doc", templateId)
+	}
+
+	values, err := GetSheetRange(sheetsSvc, spreadsheetId, sheetName, "", opts.Read)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("sheet %s has no header row", sheetName)
+	}
+	header := make([]string, len(values[0]))
+	for i, cell := range values[0] {
+		header[i] = strings.TrimSpace(CellString(cell))
+	}
+
+	pattern := opts.Name
+	if pattern == "" {
+		pattern = template.Name
+		if i := slices.IndexFunc(header, func(h string) bool { return h != "" && h != opts.IDColumn }); i >= 0 {
+			pattern += " - {{" + header[i] + "}}"
+		}
+	}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(pattern, -1) {
+		if !slices.Contains(header, m[1]) {
+			return nil, fmt.Errorf("name pattern placeholder {{%s}} is not a column of %s", m[1], sheetName)
+		}
+	}
+
+	doc, err := docsSvc.Documents.Get(templateId).IncludeTabsContent(true).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve template document: %w", err)
+	}
+	result := &MergeResult{
+		TemplateID:    templateId,
+		SpreadsheetID: spreadsheetId,
+		Sheet:         sheetName,
+		IDColumn:      opts.IDColumn,
+		Documents:     []*MergedDoc{},
+	}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(documentText(doc), -1) {
+		if !slices.Contains(header, m[1]) && !slices.Contains(result.Unmatched, m[1]) {
+			result.Unmatched = append(result.Unmatched, m[1])
+		}
+	}
+
+	if opts.PDFDir != "" && !opts.DryRun {
+		if err := os.MkdirAll(opts.PDFDir, 0o755); err != nil {
+			return nil, fmt.Errorf("unable to create PDF directory: %w", err)
+		}
+	}
+
+	// A missing ID column is added after the widest row.
+	idCol, addColumn := -1, false
+	if opts.IDColumn != "" {
+		idCol = slices.Index(header, opts.IDColumn)
+		if idCol < 0 {
+			addColumn = true
+			for _, row := range values {
+				idCol = max(idCol, len(row))
+			}
+		}
+	}
+	for i, row := range values[1:] {
+		rowNum := i + 2
+		if blankRow(row) {
+			continue
+		}
+		if idCol >= 0 && idCol < len(row) && strings.TrimSpace(CellString(row[idCol])) != "" {
+			result.Skipped = append(result.Skipped, rowNum)
+			continue
+		}
+
+		fields := make(map[string]string, len(header))
+		for j, h := range header {
+			if h == "" || j == idCol {
+				continue
+			}
+			if j < len(row) {
+				fields[h] = CellString(row[j])
+			} else {
+				fields[h] = ""
+			}
+		}
+		merged := &MergedDoc{Row: rowNum, Name: expandPlaceholders(pattern, fields)}
+		if !opts.DryRun {
+			if err := mergeRow(driveSvc, docsSvc, templateId, merged, fields, opts); err != nil {
+				err = fmt.Errorf("row %d: %w", rowNum, err)
+				if merged.DocumentID != "" {
+					result.Documents = append(result.Documents, merged)
+				}
+				if werr := writeMergedIDs(sheetsSvc, spreadsheetId, sheetName, idCol, addColumn, result); werr != nil {
+					return result, fmt.Errorf("%w; %w", err, werr)
+				}
+				return result, err
+			}
+		}
+		result.Documents = append(result.Documents, merged)
+		if opts.Progress != nil {
+			opts.Progress(merged)
+		}
+	}
+
+	if !opts.DryRun {
+		if err := writeMergedIDs(sheetsSvc, spreadsheetId, sheetName, idCol, addColumn, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// mergeRow copies the template, fills in its placeholders and, if asked,
+// exports the copy as PDF.
+func mergeRow(driveSvc *drive.Service, docsSvc *docs.Service, templateId string, merged *MergedDoc, fields map[string]string, opts MergeOptions) error {
+	copied, err := CopyFile(driveSvc, templateId, opts.Folder, merged.Name)
+	if err != nil {
+		return err
+	}
+	merged.DocumentID = copied.Id
+	merged.WebViewLink = copied.WebViewLink
+
+	var reqs []*docs.Request
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		reqs = append(reqs, &docs.Request{
+			ReplaceAllText: &docs.ReplaceAllTextRequest{
+				ContainsText: &docs.SubstringMatchCriteria{Text: "{{" + name + "}}", MatchCase: true},
+				ReplaceText:  fields[name],
+				// An empty value still removes the placeholder.
+				ForceSendFields: []string{"ReplaceText"},
+			},
+		})
+	}
+	if len(reqs) > 0 {
+		resp, err := docsSvc.Documents.BatchUpdate(copied.Id, &docs.BatchUpdateDocumentRequest{Requests: reqs}).Do()
+		if err != nil {
+			return fmt.Errorf("unable to fill placeholders in %s: %w", copied.Id, err)
+		}
+		for _, reply := range resp.Replies {
+			if reply.ReplaceAllText != nil {
+				merged.Replacements += reply.ReplaceAllText.OccurrencesChanged
+			}
+		}
+	}
+
+	if opts.PDFDir != "" {
+		pdf, err := exportGoogleAppsFile(driveSvc, copied.Id, DocumentMimeType, "pdf")
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(opts.PDFDir, safeFileName(merged.Name)+".pdf")
+		if err := os.WriteFile(path, pdf, 0o644); err != nil {
+			return fmt.Errorf("unable to write %s: %w", path, err)
+		}
+		merged.PDF = path
+	}
+	return nil
+}
+
+// writeMergedIDs writes the IDs of the generated documents into the ID
+// column, and its header when the column is new.
+func writeMergedIDs(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, idCol int, addColumn bool, result *MergeResult) error {
+	if result.IDColumn == "" {
+		return nil
+	}
+	data := make(map[string][][]interface{})
+	for _, doc := range result.Documents {
+		if doc.DocumentID == "" {
+			continue
+		}
+		if addColumn {
+			data[a1.Cell{Col: idCol}.In(sheetName).String()] = [][]interface{}{{result.IDColumn}}
+		}
+		data[a1.Cell{Col: idCol, Row: doc.Row - 1}.In(sheetName).String()] = [][]interface{}{{doc.DocumentID}}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if _, err := BatchUpdateValues(sheetsSvc, spreadsheetId, data, "raw"); err != nil {
+		return fmt.Errorf("unable to write document IDs to %s: %w", sheetName, err)
+	}
+	return nil
+}
+
+// expandPlaceholders replaces each {{column}} in s with its value in fields.
+func expandPlaceholders(s string, fields map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := fields[m[2:len(m)-2]]; ok {
+			return strings.TrimSpace(v)
+		}
+		return m
+	})
+}
+
+// safeFileName replaces the characters that cannot appear in a file name.
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
+
+// documentText returns the text of every tab of a document, including its
+// tables, headers, footers and footnotes, to search it for placeholders.
+func documentText(doc *docs.Document) string {
+	var b strings.Builder
+	var writeContent func(content []*docs.StructuralElement)
+	writeContent = func(content []*docs.StructuralElement) {
+		for _, el := range content {
+			switch {
+			case el.Paragraph != nil:
+				for _, pe := range el.Paragraph.Elements {
+					if pe.TextRun != nil {
+						b.WriteString(pe.TextRun.Content)
+					}
+				}
+			case el.Table != nil:
+				for _, row := range el.Table.TableRows {
+					for _, cell := range row.TableCells {
+						writeContent(cell.Content)
+					}
+				}
+			case el.TableOfContents != nil:
+				writeContent(el.TableOfContents.Content)
+			}
+		}
+	}
+	writeTab := func(body *docs.Body, headers map[string]docs.Header, footers map[string]docs.Footer, footnotes map[string]docs.Footnote) {
+		if body != nil {
+			writeContent(body.Content)
+		}
+		for _, h := range headers {
+			writeContent(h.Content)
+		}
+		for _, f := range footers {
+			writeContent(f.Content)
+		}
+		for _, f := range footnotes {
+			writeContent(f.Content)
+		}
+	}
+
+	if len(doc.Tabs) == 0 {
+		writeTab(doc.Body, doc.Headers, doc.Footers, doc.Footnotes)
+		return b.String()
+	}
+	var writeTabs func(tabs []*docs.Tab)
+	writeTabs = func(tabs []*docs.Tab) {
+		for _, t := range tabs {
+			if dt := t.DocumentTab; dt != nil {
+				writeTab(dt.Body, dt.Headers, dt.Footers, dt.Footnotes)
+			}
+			writeTabs(t.ChildTabs)
+		}
+	}
+	writeTabs(doc.Tabs)
+	return b.String()
+}
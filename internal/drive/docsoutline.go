@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// Heading is a heading in a Google Doc and the headings nested under it.
+type Heading struct {
+	Level     int    `json:"level"`
+	Text      string `json:"text"`
+	HeadingID string `json:"headingId,omitempty"`
+	// StartIndex is the index of the heading. EndIndex is the end of its
+	// section, which runs to the next heading of the same or a higher level.
+	StartIndex int64      `json:"startIndex"`
+	EndIndex   int64      `json:"endIndex"`
+	Children   []*Heading `json:"children,omitempty"`
+
+	// content is the index of the heading in the body content, and end is
+	// the index just past its section.
+	content int
+	end     int
+}
+
+// GetDocumentOutline returns the heading hierarchy of a Google Doc, or of one
+// of its tabs.
+func GetDocumentOutline(docsSvc *docs.Service, documentId string, tabId string) ([]*Heading, error) {
+	body, err := documentBody(docsSvc, documentId, tabId, "")
+	if err != nil {
+		return nil, err
+	}
+	return outline(body), nil
+}
+
+// GetDocumentSection renders the section under a heading as Markdown, from
+// the heading down to the next heading of the same or a higher level. The
+// heading is matched by its text, ignoring case, or by its heading ID.
+func GetDocumentSection(docsSvc *docs.Service, documentId string, tabId string, heading string) ([]byte, error) {
+	body, err := documentBody(docsSvc, documentId, tabId, "")
+	if err != nil {
+		return nil, err
+	}
+	h := findHeading(outline(body), strings.TrimSpace(heading))
+	if h == nil {
+		return nil, fmt.Errorf("heading %q not found", heading)
+	}
+	section := &docs.Body{Content: body.Content[h.content:h.end]}
+	return []byte(renderBodyAsText(section)), nil
+}
+
+// headingLevel returns the level of a heading paragraph, or 0 when the
+// paragraph is not a heading.
+func headingLevel(p *docs.Paragraph) int {
+	if p.ParagraphStyle == nil {
+		return 0
+	}
+	switch p.ParagraphStyle.NamedStyleType {
+	case "HEADING_1":
+		return 1
+	case "HEADING_2":
+		return 2
+	case "HEADING_3":
+		return 3
+	case "HEADING_4":
+		return 4
+	case "HEADING_5":
+		return 5
+	case "HEADING_6":
+		return 6
+	}
+	return 0
+}
+
+func outline(body *docs.Body) []*Heading {
+	if body == nil || len(body.Content) == 0 {
+		return nil
+	}
+	var roots, open []*Heading
+	for i, el := range body.Content {
+		if el.Paragraph == nil {
+			continue
+		}
+		level := headingLevel(el.Paragraph)
+		if level == 0 {
+			continue
+		}
+		var text strings.Builder
+		for _, pe := range el.Paragraph.Elements {
+			if pe.TextRun != nil {
+				text.WriteString(pe.TextRun.Content)
+			}
+		}
+		h := &Heading{
+			Level:      level,
+			Text:       strings.TrimSpace(text.String()),
+			HeadingID:  el.Paragraph.ParagraphStyle.HeadingId,
+			StartIndex: el.StartIndex,
+			content:    i,
+		}
+
+		// Close the sections this heading ends, then nest it under the
+		// nearest heading of a higher level.
+		for len(open) > 0 && open[len(open)-1].Level >= level {
+			closed := open[len(open)-1]
+			closed.EndIndex, closed.end = el.StartIndex, i
+			open = open[:len(open)-1]
+		}
+		if len(open) == 0 {
+			roots = append(roots, h)
+		} else {
+			parent := open[len(open)-1]
+			parent.Children = append(parent.Children, h)
+		}
+		open = append(open, h)
+	}
+	last := body.Content[len(body.Content)-1]
+	for _, h := range open {
+		h.EndIndex, h.end = last.EndIndex, len(body.Content)
+	}
+	return roots
+}
+
+func findHeading(headings []*Heading, name string) *Heading {
+	for _, h := range headings {
+		if strings.EqualFold(h.Text, name) || (h.HeadingID != "" && h.HeadingID == name) {
+			return h
+		}
+		if found := findHeading(h.Children, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
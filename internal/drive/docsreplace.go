@@ -0,0 +1,261 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// Replacement is a single find and replace.
+type Replacement struct {
+	Find    string `json:"find"`
+	Replace string `json:"replace"`
+}
+
+// ReplaceOptions controls how ReplaceDocText matches text.
+type ReplaceOptions struct {
+	// Regex treats Find as a regular expression, and lets Replace refer to
+	// its groups as $1 or ${name}.
+	Regex bool
+	// MatchCase makes matching case-sensitive.
+	MatchCase bool
+	// TabID limits the replacement to one tab. Defaults to every tab.
+	TabID string
+}
+
+// ReplaceCount is the number of times one replacement changed the document.
+type ReplaceCount struct {
+	Replacement
+	Occurrences int64 `json:"occurrences"`
+}
+
+// ReplaceResult describes the outcome of ReplaceDocText.
+type ReplaceResult struct {
+	DocumentID   string          `json:"documentId"`
+	TabID        string          `json:"tabId,omitempty"`
+	Replacements []*ReplaceCount `json:"replacements"`
+	Total        int64           `json:"total"`
+}
+
+// ReplaceDocText replaces text throughout a Google Doc, including its tables,
+// headers, footers and footnotes. Literal replacements are made with a single
+// replaceAllText request each. Regular expressions are matched against the
+// text of each paragraph, and each match is replaced by inserting the new text
+// inside the match and deleting the old, so it keeps the match's formatting.
+// Matches do not span paragraphs.
+func ReplaceDocText(docsSvc *docs.Service, documentId string, replacements []Replacement, opts ReplaceOptions) (*ReplaceResult, error) {
+	result := &ReplaceResult{DocumentID: documentId, TabID: opts.TabID, Replacements: []*ReplaceCount{}}
+	if !opts.Regex {
+		var reqs []*docs.Request
+		for _, r := range replacements {
+			if r.Find == "" {
+				return nil, fmt.Errorf("the text to find cannot be empty")
+			}
+			req := &docs.ReplaceAllTextRequest{
+				ContainsText:    &docs.SubstringMatchCriteria{Text: r.Find, MatchCase: opts.MatchCase},
+				ReplaceText:     r.Replace,
+				ForceSendFields: []string{"ReplaceText"},
+			}
+			if opts.TabID != "" {
+				req.TabsCriteria = &docs.TabsCriteria{TabIds: []string{opts.TabID}}
+			}
+			reqs = append(reqs, &docs.Request{ReplaceAllText: req})
+		}
+		if len(reqs) == 0 {
+			return result, nil
+		}
+		resp, err := docsSvc.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{Requests: reqs}).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to replace text: %w", err)
+		}
+		for i, r := range replacements {
+			count := &ReplaceCount{Replacement: r}
+			if i < len(resp.Replies) && resp.Replies[i].ReplaceAllText != nil {
+				count.Occurrences = resp.Replies[i].ReplaceAllText.OccurrencesChanged
+			}
+			result.Replacements = append(result.Replacements, count)
+			result.Total += count.Occurrences
+		}
+		return result, nil
+	}
+
+	// Each expression is matched against the document as the previous one
+	// left it, so it is fetched again for every replacement.
+	for _, r := range replacements {
+		expr := r.Find
+		if !opts.MatchCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", r.Find, err)
+		}
+		doc, err := docsSvc.Documents.Get(documentId).IncludeTabsContent(true).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve document: %w", err)
+		}
+		spans, err := documentSpans(doc, opts.TabID)
+		if err != nil {
+			return nil, err
+		}
+
+		var reqs []*docs.Request
+		var count int64
+		for _, span := range slices.Backward(spans) {
+			matches := re.FindAllStringSubmatchIndex(span.text, -1)
+			for _, m := range slices.Backward(matches) {
+				replacement := string(re.ExpandString(nil, r.Replace, span.text, m))
+				if replacement == span.text[m[0]:m[1]] {
+					continue
+				}
+				reqs = append(reqs, replaceSpanRequests(span, m[0], m[1], replacement)...)
+				count++
+			}
+		}
+		if len(reqs) > 0 {
+			_, err = docsSvc.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{Requests: reqs}).Do()
+			if err != nil {
+				return nil, fmt.Errorf("unable to replace matches of %q: %w", r.Find, err)
+			}
+		}
+		result.Replacements = append(result.Replacements, &ReplaceCount{Replacement: r, Occurrences: count})
+		result.Total += count
+	}
+	return result, nil
+}
+
+// textSpan is a run of contiguous text in one segment of a document.
+type textSpan struct {
+	tabID     string
+	segmentID string
+	start     int64
+	text      string
+}
+
+// index returns the document index of the byte offset off in the span's text.
+func (s textSpan) index(off int) int64 {
+	return s.start + int64(len(utf16.Encode([]rune(s.text[:off]))))
+}
+
+func (s textSpan) location(index int64) *docs.Location {
+	return &docs.Location{Index: index, SegmentId: s.segmentID, TabId: s.tabID}
+}
+
+func (s textSpan) rangeOf(start, end int64) *docs.Range {
+	return &docs.Range{StartIndex: start, EndIndex: end, SegmentId: s.segmentID, TabId: s.tabID}
+}
+
+// replaceSpanRequests replaces the bytes [from, to) of a span. The new text is
+// inserted after the first character of the match, so that it takes on the
+// match's style rather than that of the text before it, and the old text on
+// either side of it is then deleted. The requests only touch indexes at or
+// after the match, so matches can be replaced from last to first.
+func replaceSpanRequests(span textSpan, from, to int, replacement string) []*docs.Request {
+	start, end := span.index(from), span.index(to)
+	if replacement == "" {
+		return []*docs.Request{{DeleteContentRange: &docs.DeleteContentRangeRequest{Range: span.rangeOf(start, end)}}}
+	}
+	insert := &docs.Request{InsertText: &docs.InsertTextRequest{Location: span.location(start), Text: replacement}}
+	if start == end {
+		return []*docs.Request{insert}
+	}
+
+	_, size := utf8.DecodeRuneInString(span.text[from:])
+	first := span.index(from + size)
+	length := int64(len(utf16.Encode([]rune(replacement))))
+	insert.InsertText.Location.Index = first
+	reqs := []*docs.Request{insert}
+	if first < end {
+		reqs = append(reqs, &docs.Request{DeleteContentRange: &docs.DeleteContentRangeRequest{Range: span.rangeOf(first+length, end+length)}})
+	}
+	return append(reqs, &docs.Request{DeleteContentRange: &docs.DeleteContentRangeRequest{Range: span.rangeOf(start, first)}})
+}
+
+// documentSpans collects the text of every paragraph in a document's tabs, or
+// in one tab, as spans of contiguous text runs. A paragraph's closing newline
+// is left out so that a match cannot remove it.
+func documentSpans(doc *docs.Document, tabId string) ([]textSpan, error) {
+	var spans []textSpan
+	var walk func(tabID, segmentID string, content []*docs.StructuralElement)
+	walk = func(tabID, segmentID string, content []*docs.StructuralElement) {
+		for _, el := range content {
+			switch {
+			case el.Paragraph != nil:
+				var span *textSpan
+				for _, pe := range el.Paragraph.Elements {
+					if pe.TextRun == nil {
+						span = nil
+						continue
+					}
+					if span != nil && span.index(len(span.text)) == pe.StartIndex {
+						span.text += pe.TextRun.Content
+						continue
+					}
+					spans = append(spans, textSpan{tabID: tabID, segmentID: segmentID, start: pe.StartIndex, text: pe.TextRun.Content})
+					span = &spans[len(spans)-1]
+				}
+				if span != nil && len(span.text) > 0 && span.text[len(span.text)-1] == '\n' {
+					span.text = span.text[:len(span.text)-1]
+				}
+			case el.Table != nil:
+				for _, row := range el.Table.TableRows {
+					for _, cell := range row.TableCells {
+						walk(tabID, segmentID, cell.Content)
+					}
+				}
+			}
+		}
+	}
+	walkTab := func(tabID string, dt *docs.DocumentTab) {
+		if dt.Body != nil {
+			walk(tabID, "", dt.Body.Content)
+		}
+		for id, h := range dt.Headers {
+			walk(tabID, id, h.Content)
+		}
+		for id, f := range dt.Footers {
+			walk(tabID, id, f.Content)
+		}
+		for id, f := range dt.Footnotes {
+			walk(tabID, id, f.Content)
+		}
+	}
+
+	if tabId != "" {
+		tab := findTab(doc.Tabs, tabId)
+		if tab == nil || tab.DocumentTab == nil {
+			return nil, fmt.Errorf("tab with id %s not found", tabId)
+		}
+		walkTab(tabId, tab.DocumentTab)
+		return spans, nil
+	}
+	var walkTabs func(tabs []*docs.Tab)
+	walkTabs = func(tabs []*docs.Tab) {
+		for _, t := range tabs {
+			if t.DocumentTab != nil && t.TabProperties != nil {
+				walkTab(t.TabProperties.TabId, t.DocumentTab)
+			}
+			walkTabs(t.ChildTabs)
+		}
+	}
+	walkTabs(doc.Tabs)
+	return spans, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// DocTableOptions controls where and how InsertDocTable inserts a table.
+type DocTableOptions struct {
+	TableOptions
+	// TabID is the tab to insert into. Defaults to the first tab.
+	TabID string
+	// Index is the position in the document body to insert the table at.
+	// Zero appends the table to the end of the body.
+	Index int64
+}
+
+// DocTableResult describes a table inserted into a document.
+type DocTableResult struct {
+	DocumentID string `json:"documentId"`
+	TabID      string `json:"tabId,omitempty"`
+	StartIndex int64  `json:"startIndex"`
+	Rows       int    `json:"rows"`
+	Columns    int    `json:"columns"`
+	Header     bool   `json:"header"`
+}
+
+// InsertDocTable inserts a grid of values into a Google Doc as a native table.
+// A header row is made bold and pinned, and numeric columns are right-aligned.
+func InsertDocTable(docsSvc *docs.Service, documentId string, values [][]interface{}, opts DocTableOptions) (*DocTableResult, error) {
+	layout, err := LayoutTable(values, opts.TableOptions)
+	if err != nil {
+		return nil, err
+	}
+	if layout.Width == 0 {
+		return nil, fmt.Errorf("there are no values to insert")
+	}
+
+	insert := &docs.InsertTableRequest{Rows: int64(len(values)), Columns: int64(layout.Width)}
+	if opts.Index > 0 {
+		insert.Location = &docs.Location{Index: opts.Index, TabId: opts.TabID}
+	} else {
+		insert.EndOfSegmentLocation = &docs.EndOfSegmentLocation{TabId: opts.TabID}
+	}
+	_, err = docsSvc.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{
+		Requests: []*docs.Request{{InsertTable: insert}},
+	}).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to insert table: %w", err)
+	}
+
+	// Find the new table to learn the index of each cell.
+	body, err := documentBody(docsSvc, documentId, opts.TabID, "")
+	if err != nil {
+		return nil, err
+	}
+	var table *docs.StructuralElement
+	for _, el := range body.Content {
+		if el.Table == nil {
+			continue
+		}
+		if opts.Index > 0 && el.StartIndex >= opts.Index {
+			table = el
+			break
+		}
+		if opts.Index == 0 {
+			table = el
+		}
+	}
+	if table == nil || len(table.Table.TableRows) != len(values) {
+		return nil, fmt.Errorf("unable to find the inserted table")
+	}
+
+	// Fill the cells from last to first, so each insertion leaves the indexes
+	// of the cells before it unchanged, then style them at their final positions.
+	type cell struct {
+		start int64
+		text  string
+		row   int
+		col   int
+	}
+	var cells []cell
+	for r, row := range table.Table.TableRows {
+		for c, tc := range row.TableCells {
+			text := ""
+			if c < len(values[r]) {
+				text = strings.TrimSpace(CellString(values[r][c]))
+			}
+			cells = append(cells, cell{start: tc.Content[0].StartIndex, text: text, row: r, col: c})
+		}
+	}
+	var reqs []*docs.Request
+	for i := len(cells) - 1; i >= 0; i-- {
+		if cells[i].text == "" {
+			continue
+		}
+		reqs = append(reqs, &docs.Request{
+			InsertText: &docs.InsertTextRequest{
+				Location: &docs.Location{Index: cells[i].start, TabId: opts.TabID},
+				Text:     cells[i].text,
+			},
+		})
+	}
+	var shift int64
+	for _, c := range cells {
+		start := c.start + shift
+		length := int64(len(utf16.Encode([]rune(c.text))))
+		shift += length
+		if layout.Header && c.row == 0 && length > 0 {
+			reqs = append(reqs, &docs.Request{
+				UpdateTextStyle: &docs.UpdateTextStyleRequest{
+					Range:     &docs.Range{StartIndex: start, EndIndex: start + length, TabId: opts.TabID},
+					TextStyle: &docs.TextStyle{Bold: true},
+					Fields:    "bold",
+				},
+			})
+		}
+		if c.col < len(layout.Numeric) && layout.Numeric[c.col] {
+			reqs = append(reqs, &docs.Request{
+				UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+					Range:          &docs.Range{StartIndex: start, EndIndex: start + max(length, 1), TabId: opts.TabID},
+					ParagraphStyle: &docs.ParagraphStyle{Alignment: "END"},
+					Fields:         "alignment",
+				},
+			})
+		}
+	}
+	if layout.Header && len(values) > 1 {
+		reqs = append(reqs, &docs.Request{
+			PinTableHeaderRows: &docs.PinTableHeaderRowsRequest{
+				TableStartLocation:    &docs.Location{Index: table.StartIndex, TabId: opts.TabID},
+				PinnedHeaderRowsCount: 1,
+			},
+		})
+	}
+
+	if len(reqs) > 0 {
+		_, err = docsSvc.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{Requests: reqs}).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to fill table: %w", err)
+		}
+	}
+	return &DocTableResult{
+		DocumentID: documentId,
+		TabID:      opts.TabID,
+		StartIndex: table.StartIndex,
+		Rows:       len(values),
+		Columns:    layout.Width,
+		Header:     layout.Header,
+	}, nil
+}
@@ -13,7 +13,7 @@ import (
 
 // ListFiles lists the files and folders in Google Drive.
 func ListFiles(srv *drive.Service, limit int64, query string) ([]*drive.File, error) {
-	r, err := srv.Files.List().PageSize(limit).Q(query).
+	r, err := scopeList(srv.Files.List(), false).PageSize(limit).Q(query).
 		Fields("nextPageToken, files(id, name)").Do()
 	if err != nil {
 		return nil, err
@@ -40,6 +40,12 @@ var formatMap = map[string]string{
 
 // renderBodyAsText converts a Google Docs Body object to a Markdown string.
 func renderBodyAsText(body *docs.Body) string {
+	return renderBodyAsMarkdown(body, false)
+}
+
+// renderBodyAsMarkdown converts a Google Docs Body object to a Markdown string,
+// marking suggested insertions and deletions with CriticMarkup when inlineSuggestions is set.
+func renderBodyAsMarkdown(body *docs.Body, inlineSuggestions bool) string {
 	var text strings.Builder
 	if body == nil || body.Content == nil {
 		return ""
@@ -88,6 +94,9 @@ func renderBodyAsText(body *docs.Body) string {
 							content = fmt.Sprintf("[%s](%s) ", strings.TrimSpace(content), style.Link.Url)
 						}
 					}
+					if inlineSuggestions {
+						content = markSuggestion(content, pElem.TextRun)
+					}
 					text.WriteString(content)
 				}
 			}
@@ -110,24 +119,49 @@ func findTab(tabs []*docs.Tab, tabId string) *docs.Tab {
 	return nil
 }
 
-func getDocumentTabContent(docsSvc *docs.Service, fileId string, tabId string) ([]byte, error) {
-	doc, err := docsSvc.Documents.Get(fileId).IncludeTabsContent(true).Do()
+// documentBody fetches a document and returns the body of the given tab, or
+// of the first tab when tabId is empty. An empty viewMode uses the API's
+// default suggestions view mode.
+func documentBody(docsSvc *docs.Service, documentId string, tabId string, viewMode string) (*docs.Body, error) {
+	call := docsSvc.Documents.Get(documentId)
+	if viewMode != "" {
+		call = call.SuggestionsViewMode(viewMode)
+	}
+	if tabId == "" {
+		doc, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve document: %w", err)
+		}
+		return doc.Body, nil
+	}
+	doc, err := call.IncludeTabsContent(true).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve document with tabs: %w", err)
 	}
-
-	if tab := findTab(doc.Tabs, tabId); tab != nil {
-		textContent := renderBodyAsText(tab.DocumentTab.Body)
-		return []byte(textContent), nil
+	tab := findTab(doc.Tabs, tabId)
+	if tab == nil || tab.DocumentTab == nil {
+		return nil, fmt.Errorf("tab with id %s not found", tabId)
 	}
+	return tab.DocumentTab.Body, nil
+}
 
-	return nil, fmt.Errorf("tab with id %s not found", tabId)
+func getDocumentTabContent(docsSvc *docs.Service, fileId string, tabId string, suggestions string) ([]byte, error) {
+	viewMode, ok := suggestionsViewModes[suggestions]
+	if !ok && suggestions != "" {
+		return nil, fmt.Errorf("invalid suggestions option: %s. Valid options are: accept, reject, inline", suggestions)
+	}
+	body, err := documentBody(docsSvc, fileId, tabId, viewMode)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(renderBodyAsMarkdown(body, suggestions == "inline")), nil
 }
 
-func exportGoogleAppsFile(driveSvc *drive.Service, fileId string, mimeType string, format string) ([]byte, error) {
+// exportMimeTypeFor maps a user-facing format to the MIME type a Google Apps file is exported as.
+func exportMimeTypeFor(mimeType string, format string) (string, error) {
 	exportMimeType, ok := formatMap[strings.ToLower(format)]
 	if !ok && format != "" {
-		return nil, fmt.Errorf("invalid format: %s. Valid formats are: pdf, docx, html, zip, epub, txt, md, csv, tsv, xlsx, ods, pptx, odp", format)
+		return "", fmt.Errorf("invalid format: %s. Valid formats are: pdf, docx, html, zip, epub, txt, md, csv, tsv, xlsx, ods, pptx, odp", format)
 	}
 
 	if mimeType == "application/vnd.google-apps.spreadsheet" {
@@ -137,6 +171,14 @@ func exportGoogleAppsFile(driveSvc *drive.Service, fileId string, mimeType strin
 	} else if format == "" {
 		exportMimeType = "text/plain"
 	}
+	return exportMimeType, nil
+}
+
+func exportGoogleAppsFile(driveSvc *drive.Service, fileId string, mimeType string, format string) ([]byte, error) {
+	exportMimeType, err := exportMimeTypeFor(mimeType, format)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := driveSvc.Files.Export(fileId, exportMimeType).Download()
 	if err != nil {
@@ -152,7 +194,7 @@ func exportGoogleAppsFile(driveSvc *drive.Service, fileId string, mimeType strin
 }
 
 func downloadStandardFile(driveSvc *drive.Service, fileId string) ([]byte, error) {
-	resp, err := driveSvc.Files.Get(fileId).Download()
+	resp, err := driveSvc.Files.Get(fileId).SupportsAllDrives(true).Download()
 	if err != nil {
 		return nil, fmt.Errorf("unable to download file: %w", err)
 	}
@@ -165,17 +207,30 @@ func downloadStandardFile(driveSvc *drive.Service, fileId string) ([]byte, error
 	return body, nil
 }
 
-// GetFile downloads a file or exports a Google Doc.
-func GetFile(driveSvc *drive.Service, docsSvc *docs.Service, fileId string, format string, tabId string) ([]byte, error) {
+// GetFile downloads a file or exports a Google Doc. For Google Docs rendered
+// as Markdown, suggestions is accept, reject or inline, and chooses how
+// pending suggestions appear; empty leaves it to the API's default. Setting
+// it renders the Markdown with renderBodyAsMarkdown instead of Drive's
+// export, which loses tables, numbered lists and combined styles.
+func GetFile(driveSvc *drive.Service, docsSvc *docs.Service, fileId string, format string, tabId string, suggestions string) ([]byte, error) {
 	if tabId != "" {
-		return getDocumentTabContent(docsSvc, fileId, tabId)
+		return getDocumentTabContent(docsSvc, fileId, tabId, suggestions)
 	}
 
-	file, err := driveSvc.Files.Get(fileId).Fields("mimeType", "name").Do()
+	file, err := driveSvc.Files.Get(fileId).SupportsAllDrives(true).Fields("mimeType", "name").Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve file metadata: %w", err)
 	}
 
+	if suggestions != "" {
+		// Drive exports cannot choose how suggestions are shown, so Markdown is
+		// rendered from the Docs API instead.
+		if file.MimeType != DocumentMimeType || (format != "md" && format != "markdown") {
+			return nil, fmt.Errorf("suggestions can only be chosen for Google Docs exported as md, or with a tab ID")
+		}
+		return getDocumentTabContent(docsSvc, fileId, "", suggestions)
+	}
+
 	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps") {
 		return exportGoogleAppsFile(driveSvc, fileId, file.MimeType, format)
 	}
@@ -185,7 +240,7 @@ func GetFile(driveSvc *drive.Service, docsSvc *docs.Service, fileId string, form
 
 // DescribeFile shows detailed metadata for a specific file.
 func DescribeFile(driveSvc *drive.Service, fileId string) (*drive.File, error) {
-	file, err := driveSvc.Files.Get(fileId).Fields("*").Do()
+	file, err := driveSvc.Files.Get(fileId).SupportsAllDrives(true).Fields("*").Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve file: %w", err)
 	}
@@ -273,9 +328,11 @@ func UploadFile(srv *drive.Service, filePath string, parentID string) (*drive.Fi
 	}
 	if parentID != "" {
 		f.Parents = []string{parentID}
+	} else if scope.DriveID != "" {
+		f.Parents = []string{scope.DriveID}
 	}
 
-	res, err := srv.Files.Create(f).Media(file).Do()
+	res, err := srv.Files.Create(f).SupportsAllDrives(true).Media(file).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create file %s: %w", fileName, err)
 	}
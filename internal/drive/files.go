@@ -0,0 +1,242 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// FolderMimeType is the MIME type Google Drive uses for folders.
+const FolderMimeType = "application/vnd.google-apps.folder"
+
+const fileOpFields = "id, name, mimeType, parents, trashed, webViewLink"
+
+// escapeQuery escapes a value for use inside a single-quoted Drive query string.
+func escapeQuery(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, `'`, `\'`)
+}
+
+// ListChildren lists every non-trashed item directly inside a folder, following pagination.
+func ListChildren(srv *drive.Service, folderID string) ([]*drive.File, error) {
+	var files []*drive.File
+	q := fmt.Sprintf("'%s' in parents and trashed = false", escapeQuery(folderID))
+	pageToken := ""
+	for {
+		r, err := scopeList(srv.Files.List(), true).Q(q).PageSize(1000).PageToken(pageToken).
+			Fields("nextPageToken, files(id, name, mimeType, parents, webViewLink)").Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list folder %s: %w", folderID, err)
+		}
+		files = append(files, r.Files...)
+		if r.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = r.NextPageToken
+	}
+}
+
+// CreateFolder creates a folder with the given name inside parentID.
+func CreateFolder(srv *drive.Service, name string, parentID string) (*drive.File, error) {
+	f := &drive.File{
+		Name:     name,
+		MimeType: FolderMimeType,
+	}
+	if parentID == "" {
+		parentID = defaultParent()
+	}
+	f.Parents = []string{parentID}
+	res, err := srv.Files.Create(f).SupportsAllDrives(true).Fields(fileOpFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create folder %s: %w", name, err)
+	}
+	return res, nil
+}
+
+// findFolder returns the first non-trashed folder called name inside parentID, or nil.
+func findFolder(srv *drive.Service, name string, parentID string) (*drive.File, error) {
+	q := fmt.Sprintf("name = '%s' and mimeType = '%s' and '%s' in parents and trashed = false",
+		escapeQuery(name), FolderMimeType, escapeQuery(parentID))
+	r, err := scopeList(srv.Files.List(), true).Q(q).PageSize(1).Fields("files(" + fileOpFields + ")").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to search for folder %s: %w", name, err)
+	}
+	if len(r.Files) == 0 {
+		return nil, nil
+	}
+	return r.Files[0], nil
+}
+
+// MkdirAll creates every folder along a slash-separated path, reusing folders
+// that already exist, and returns the innermost folder. parentID defaults to the root of the current drive.
+func MkdirAll(srv *drive.Service, path string, parentID string) (*drive.File, error) {
+	if parentID == "" {
+		parentID = defaultParent()
+	}
+	var current *drive.File
+	for _, name := range strings.Split(path, "/") {
+		if name == "" {
+			continue
+		}
+		existing, err := findFolder(srv, name, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			existing, err = CreateFolder(srv, name, parentID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		current = existing
+		parentID = existing.Id
+	}
+	if current == nil {
+		return nil, fmt.Errorf("invalid folder path: %q", path)
+	}
+	return current, nil
+}
+
+// MoveFile re-parents a file so that newParentID becomes its only parent.
+func MoveFile(srv *drive.Service, fileId string, newParentID string) (*drive.File, error) {
+	file, err := srv.Files.Get(fileId).SupportsAllDrives(true).Fields("parents").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve file %s: %w", fileId, err)
+	}
+
+	res, err := srv.Files.Update(fileId, &drive.File{}).
+		AddParents(newParentID).
+		RemoveParents(strings.Join(file.Parents, ",")).
+		SupportsAllDrives(true).
+		Fields(fileOpFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to move file %s: %w", fileId, err)
+	}
+	return res, nil
+}
+
+// CopyFile makes a server-side copy of a file. Folders are copied recursively.
+// An empty name keeps the original name; an empty parentID keeps the original parent.
+func CopyFile(srv *drive.Service, fileId string, parentID string, name string) (*drive.File, error) {
+	return copyFile(srv, fileId, parentID, name, true)
+}
+
+// copyFile copies a file. checkTarget guards against copying a folder into
+// itself; it is only needed for the top-level copy, since the folders the
+// copy recurses into are newly created.
+func copyFile(srv *drive.Service, fileId string, parentID string, name string, checkTarget bool) (*drive.File, error) {
+	src, err := srv.Files.Get(fileId).SupportsAllDrives(true).Fields(fileOpFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve file %s: %w", fileId, err)
+	}
+	if name == "" {
+		name = src.Name
+	}
+	if parentID == "" && len(src.Parents) > 0 {
+		parentID = src.Parents[0]
+	}
+
+	if src.MimeType != FolderMimeType {
+		f := &drive.File{Name: name}
+		if parentID != "" {
+			f.Parents = []string{parentID}
+		}
+		res, err := srv.Files.Copy(fileId, f).SupportsAllDrives(true).Fields(fileOpFields).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to copy file %s: %w", fileId, err)
+		}
+		return res, nil
+	}
+
+	// A folder cannot be copied into itself, and its children are listed
+	// before the copy is created so the copy is never among them.
+	if checkTarget {
+		within, err := isWithin(srv, parentID, fileId)
+		if err != nil {
+			return nil, err
+		}
+		if within {
+			return nil, fmt.Errorf("unable to copy folder %s into itself or one of its subfolders", fileId)
+		}
+	}
+	children, err := ListChildren(srv, fileId)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := CreateFolder(srv, name, parentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if _, err := copyFile(srv, child.Id, folder.Id, "", false); err != nil {
+			return nil, err
+		}
+	}
+	return folder, nil
+}
+
+// isWithin reports whether the file fileId is the folder ancestorId or is
+// inside it, at any depth.
+func isWithin(srv *drive.Service, fileId string, ancestorId string) (bool, error) {
+	seen := make(map[string]bool)
+	for id := fileId; id != "" && !seen[id]; {
+		if id == ancestorId {
+			return true, nil
+		}
+		seen[id] = true
+		f, err := srv.Files.Get(id).SupportsAllDrives(true).Fields("id, parents").Do()
+		if err != nil {
+			return false, fmt.Errorf("unable to retrieve folder %s: %w", id, err)
+		}
+		id = ""
+		if len(f.Parents) > 0 {
+			id = f.Parents[0]
+		}
+	}
+	return false, nil
+}
+
+// RenameFile changes the name of a file.
+func RenameFile(srv *drive.Service, fileId string, name string) (*drive.File, error) {
+	res, err := srv.Files.Update(fileId, &drive.File{Name: name}).SupportsAllDrives(true).Fields(fileOpFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to rename file %s: %w", fileId, err)
+	}
+	return res, nil
+}
+
+// TrashFile moves a file to the trash, or restores it when trashed is false.
+func TrashFile(srv *drive.Service, fileId string, trashed bool) (*drive.File, error) {
+	f := &drive.File{Trashed: trashed}
+	if !trashed {
+		f.ForceSendFields = []string{"Trashed"}
+	}
+	res, err := srv.Files.Update(fileId, f).SupportsAllDrives(true).Fields(fileOpFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update trash state of file %s: %w", fileId, err)
+	}
+	return res, nil
+}
+
+// DeleteFile permanently deletes a file, bypassing the trash.
+func DeleteFile(srv *drive.Service, fileId string) error {
+	if err := srv.Files.Delete(fileId).SupportsAllDrives(true).Do(); err != nil {
+		return fmt.Errorf("unable to delete file %s: %w", fileId, err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+const permissionFields = "id, type, role, emailAddress, domain, displayName, expirationTime, allowFileDiscovery, deleted"
+
+// PermissionChange describes one difference between two access control lists.
+type PermissionChange struct {
+	Op           string `json:"op"`
+	Type         string `json:"type"`
+	Target       string `json:"target"`
+	Role         string `json:"role,omitempty"`
+	PreviousRole string `json:"previousRole,omitempty"`
+	PermissionID string `json:"permissionId,omitempty"`
+	// ExpirationTime and AllowFileDiscovery are set when they are granted or changed.
+	ExpirationTime             string `json:"expirationTime,omitempty"`
+	PreviousExpirationTime     string `json:"previousExpirationTime,omitempty"`
+	AllowFileDiscovery         *bool  `json:"allowFileDiscovery,omitempty"`
+	PreviousAllowFileDiscovery *bool  `json:"previousAllowFileDiscovery,omitempty"`
+}
+
+// PermissionUpdate holds the fields of a permission to change. Empty fields
+// are left untouched.
+type PermissionUpdate struct {
+	Role           string
+	ExpirationTime string
+	// AllowFileDiscovery only applies to domain and anyone permissions.
+	AllowFileDiscovery *bool
+}
+
+// ShareOptions controls how a new permission is announced to its grantee.
+type ShareOptions struct {
+	Notify  bool
+	Message string
+}
+
+// PermissionTarget returns the grantee of a permission in human-readable form.
+func PermissionTarget(p *drive.Permission) string {
+	switch p.Type {
+	case "user", "group":
+		return p.EmailAddress
+	case "domain":
+		return p.Domain
+	case "anyone":
+		if p.AllowFileDiscovery {
+			return "anyone"
+		}
+		return "anyone with the link"
+	}
+	return p.Id
+}
+
+// samePermissionGrantee reports whether two permissions apply to the same
+// grantee. Anyone who can find the file in search and anyone with the link are
+// different grantees.
+func samePermissionGrantee(a, b *drive.Permission) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case "user", "group":
+		return strings.EqualFold(a.EmailAddress, b.EmailAddress)
+	case "domain":
+		return strings.EqualFold(a.Domain, b.Domain)
+	case "anyone":
+		return a.AllowFileDiscovery == b.AllowFileDiscovery
+	}
+	return true
+}
+
+// FindPermission returns the permission in acl that applies to the same grantee as want, or nil.
+func FindPermission(acl []*drive.Permission, want *drive.Permission) *drive.Permission {
+	for _, p := range acl {
+		if samePermissionGrantee(p, want) {
+			return p
+		}
+	}
+	return nil
+}
+
+// ListPermissions retrieves every permission on a file, following pagination.
+func ListPermissions(srv *drive.Service, fileId string) ([]*drive.Permission, error) {
+	var perms []*drive.Permission
+	pageToken := ""
+	for {
+		r, err := srv.Permissions.List(fileId).SupportsAllDrives(true).PageSize(100).PageToken(pageToken).
+			Fields("nextPageToken, permissions(" + permissionFields + ")").Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list permissions for %s: %w", fileId, err)
+		}
+		perms = append(perms, r.Permissions...)
+		if r.NextPageToken == "" {
+			return perms, nil
+		}
+		pageToken = r.NextPageToken
+	}
+}
+
+// CreatePermission grants a new permission on a file.
+// Notification options only apply to user and group grantees.
+func CreatePermission(srv *drive.Service, fileId string, perm *drive.Permission, opts ShareOptions) (*drive.Permission, error) {
+	call := srv.Permissions.Create(fileId, perm).SupportsAllDrives(true).Fields(permissionFields)
+	if perm.Type == "user" || perm.Type == "group" {
+		call = call.SendNotificationEmail(opts.Notify)
+		if opts.Notify && opts.Message != "" {
+			call = call.EmailMessage(opts.Message)
+		}
+	}
+	res, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to share %s with %s: %w", fileId, PermissionTarget(perm), err)
+	}
+	return res, nil
+}
+
+// UpdatePermission changes the role, expiration or discoverability of an
+// existing permission.
+func UpdatePermission(srv *drive.Service, fileId string, permissionId string, update PermissionUpdate) (*drive.Permission, error) {
+	perm := &drive.Permission{
+		Role:           update.Role,
+		ExpirationTime: update.ExpirationTime,
+	}
+	if update.AllowFileDiscovery != nil {
+		perm.AllowFileDiscovery = *update.AllowFileDiscovery
+		perm.ForceSendFields = []string{"AllowFileDiscovery"}
+	}
+	res, err := srv.Permissions.Update(fileId, permissionId, perm).SupportsAllDrives(true).Fields(permissionFields).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update permission %s on %s: %w", permissionId, fileId, err)
+	}
+	return res, nil
+}
+
+// DeletePermission revokes a permission from a file.
+func DeletePermission(srv *drive.Service, fileId string, permissionId string) error {
+	if err := srv.Permissions.Delete(fileId, permissionId).SupportsAllDrives(true).Do(); err != nil {
+		return fmt.Errorf("unable to remove permission %s from %s: %w", permissionId, fileId, err)
+	}
+	return nil
+}
+
+// DiffPermissions compares two access control lists and returns the changes
+// needed to turn before into after. An expiration that is empty in after is
+// left as it is.
+func DiffPermissions(before, after []*drive.Permission) []*PermissionChange {
+	var changes []*PermissionChange
+	for _, a := range after {
+		b := FindPermission(before, a)
+		if b == nil {
+			c := &PermissionChange{Op: "add", Type: a.Type, Target: PermissionTarget(a), Role: a.Role, ExpirationTime: a.ExpirationTime}
+			if discoverable(a) {
+				c.AllowFileDiscovery = &a.AllowFileDiscovery
+			}
+			changes = append(changes, c)
+			continue
+		}
+		expires := a.ExpirationTime != "" && !sameTime(a.ExpirationTime, b.ExpirationTime)
+		discovery := discoverable(a) && a.AllowFileDiscovery != b.AllowFileDiscovery
+		if b.Role == a.Role && !expires && !discovery {
+			continue
+		}
+		c := &PermissionChange{Op: "change", Type: a.Type, Target: PermissionTarget(a), Role: a.Role, PreviousRole: b.Role, PermissionID: b.Id}
+		if expires {
+			c.ExpirationTime, c.PreviousExpirationTime = a.ExpirationTime, b.ExpirationTime
+		}
+		if discovery {
+			c.AllowFileDiscovery, c.PreviousAllowFileDiscovery = &a.AllowFileDiscovery, &b.AllowFileDiscovery
+		}
+		changes = append(changes, c)
+	}
+	for _, b := range before {
+		if FindPermission(after, b) == nil {
+			changes = append(changes, &PermissionChange{Op: "remove", Type: b.Type, Target: PermissionTarget(b), PreviousRole: b.Role, PermissionID: b.Id})
+		}
+	}
+	return changes
+}
+
+// discoverable reports whether file discovery applies to a permission.
+func discoverable(p *drive.Permission) bool {
+	return p.Type == "domain" || p.Type == "anyone"
+}
+
+// sameTime reports whether two RFC 3339 timestamps are the same instant, so
+// that 2025-01-01T00:00:00Z matches 2025-01-01T00:00:00.000Z.
+func sameTime(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ta.Equal(tb)
+}
+
+// WalkFolder calls fn for a file and, if it is a folder, for every descendant.
+// The path passed to fn is slash-separated and relative to the starting file.
+func WalkFolder(srv *drive.Service, fileId string, fn func(file *drive.File, path string) error) error {
+	root, err := srv.Files.Get(fileId).SupportsAllDrives(true).Fields(fileOpFields).Do()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve file %s: %w", fileId, err)
+	}
+	return walkFolder(srv, root, root.Name, fn)
+}
+
+func walkFolder(srv *drive.Service, file *drive.File, path string, fn func(file *drive.File, path string) error) error {
+	if err := fn(file, path); err != nil {
+		return err
+	}
+	if file.MimeType != FolderMimeType {
+		return nil
+	}
+	children, err := ListChildren(srv, file.Id)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := walkFolder(srv, child, path+"/"+child.Name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
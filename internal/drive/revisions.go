@@ -15,17 +15,141 @@
 package drive
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 )
 
 // ListRevisions retrieves a list of revisions for a given file ID.
 func ListRevisions(srv *drive.Service, fileId string) ([]*drive.Revision, error) {
-	call := srv.Revisions.List(fileId).Fields("revisions(id,modifiedTime,lastModifyingUser(displayName,emailAddress),size,originalFilename,mimeType)")
+	call := srv.Revisions.List(fileId).Fields("revisions(id,modifiedTime,lastModifyingUser(displayName,emailAddress),size,originalFilename,mimeType,keepForever)")
 	resp, err := call.Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to list revisions: %w", err)
 	}
 	return resp.Revisions, nil
 }
+
+// GetRevisionContent downloads the content of a specific revision. Revisions of
+// Google Docs, Sheets and Slides are exported in the given format, as with GetFile.
+func GetRevisionContent(srv *drive.Service, client *http.Client, fileId string, revisionId string, format string) ([]byte, error) {
+	file, err := srv.Files.Get(fileId).SupportsAllDrives(true).Fields("mimeType").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve file metadata: %w", err)
+	}
+
+	if !strings.HasPrefix(file.MimeType, "application/vnd.google-apps") {
+		resp, err := srv.Revisions.Get(fileId, revisionId).Download()
+		if err != nil {
+			return nil, fmt.Errorf("unable to download revision %s: %w", revisionId, err)
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	}
+
+	exportMimeType, err := exportMimeTypeFor(file.MimeType, format)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := srv.Revisions.Get(fileId, revisionId).Fields("id,exportLinks").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve revision %s: %w", revisionId, err)
+	}
+	link, ok := rev.ExportLinks[exportMimeType]
+	if !ok {
+		var available []string
+		for mt := range rev.ExportLinks {
+			available = append(available, mt)
+		}
+		return nil, fmt.Errorf("revision %s cannot be exported as %s (available: %s)", revisionId, exportMimeType, strings.Join(available, ", "))
+	}
+
+	resp, err := client.Get(link)
+	if err != nil {
+		return nil, fmt.Errorf("unable to export revision %s: %w", revisionId, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read exported revision: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("unable to export revision %s (HTTP %d): %s", revisionId, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// KeepRevision pins a revision so that it is never automatically purged, or unpins it when keep is false.
+func KeepRevision(srv *drive.Service, fileId string, revisionId string, keep bool) (*drive.Revision, error) {
+	rev := &drive.Revision{
+		KeepForever:     keep,
+		ForceSendFields: []string{"KeepForever"},
+	}
+	res, err := srv.Revisions.Update(fileId, revisionId, rev).Fields("id,modifiedTime,keepForever").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update revision %s: %w", revisionId, err)
+	}
+	return res, nil
+}
+
+// RestoreRevision re-uploads the content of an old revision as the current content
+// of a file. Only binary files are supported; Google Docs, Sheets and Slides
+// revisions cannot be uploaded back in their native format.
+func RestoreRevision(srv *drive.Service, client *http.Client, fileId string, revisionId string) (*drive.File, error) {
+	file, err := srv.Files.Get(fileId).SupportsAllDrives(true).Fields("mimeType").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve file metadata: %w", err)
+	}
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps") {
+		return nil, fmt.Errorf("restoring revisions of %s files is not supported; use the version history in the Google editor instead", file.MimeType)
+	}
+
+	content, err := GetRevisionContent(srv, client, fileId, revisionId, "")
+	if err != nil {
+		return nil, err
+	}
+	res, err := srv.Files.Update(fileId, &drive.File{}).SupportsAllDrives(true).
+		Media(bytes.NewReader(content), googleapi.ContentType(file.MimeType)).Fields("id,name,mimeType,headRevisionId,modifiedTime").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore revision %s: %w", revisionId, err)
+	}
+	return res, nil
+}
+
+// GetSheetRevisionValues exports one tab of an earlier spreadsheet revision as
+// a grid of values. The tab is chosen by its sheet ID.
+func GetSheetRevisionValues(srv *drive.Service, client *http.Client, spreadsheetId string, revisionId string, sheetId int64) ([][]interface{}, error) {
+	rev, err := srv.Revisions.Get(spreadsheetId, revisionId).Fields("id,exportLinks").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve revision %s: %w", revisionId, err)
+	}
+	link, ok := rev.ExportLinks["text/csv"]
+	if !ok {
+		return nil, fmt.Errorf("revision %s cannot be exported as CSV", revisionId)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export link for revision %s: %w", revisionId, err)
+	}
+	// The CSV export covers a single tab, selected by the gid parameter.
+	q := u.Query()
+	q.Set("gid", strconv.FormatInt(sheetId, 10))
+	u.RawQuery = q.Encode()
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to export revision %s: %w", revisionId, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to export revision %s: %s", revisionId, resp.Status)
+	}
+	return ReadCSVGrid(resp.Body, ',')
+}
@@ -1,72 +1,543 @@
 package drive
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/ghchinoy/drivectl/internal/a1"
 	"google.golang.org/api/sheets/v4"
 )
 
+// ValueRenderOptions controls how cell values are rendered when read.
+type ValueRenderOptions struct {
+	// Render is "formatted" (the default), "unformatted" or "formula".
+	Render string
+	// DateTimeRender is "serial" (the default) or "formatted". It only applies
+	// when values are not formatted.
+	DateTimeRender string
+}
+
+// valueRenderOption maps a render name to a Sheets ValueRenderOption.
+func valueRenderOption(render string) (string, error) {
+	switch render {
+	case "", "formatted":
+		return "FORMATTED_VALUE", nil
+	case "unformatted":
+		return "UNFORMATTED_VALUE", nil
+	case "formula":
+		return "FORMULA", nil
+	}
+	return "", fmt.Errorf("invalid render option: %s. Valid options are: formatted, unformatted, formula", render)
+}
+
+// dateTimeRenderOption maps a date-time render name to a Sheets DateTimeRenderOption.
+func dateTimeRenderOption(render string) (string, error) {
+	switch render {
+	case "", "serial":
+		return "SERIAL_NUMBER", nil
+	case "formatted":
+		return "FORMATTED_STRING", nil
+	}
+	return "", fmt.Errorf("invalid date-time render option: %s. Valid options are: serial, formatted", render)
+}
+
+// GetSheetValues reads the values in an A1 range, which may be a whole sheet name.
+// An empty range yields no rows and no error.
+func GetSheetValues(sheetsSvc *sheets.Service, spreadsheetId string, readRange string, opts ValueRenderOptions) ([][]interface{}, error) {
+	render, err := valueRenderOption(opts.Render)
+	if err != nil {
+		return nil, err
+	}
+	dateTimeRender, err := dateTimeRenderOption(opts.DateTimeRender)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sheetsSvc.Spreadsheets.Values.Get(spreadsheetId, readRange).
+		ValueRenderOption(render).DateTimeRenderOption(dateTimeRender).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve data from sheet: %w", err)
+	}
+	return resp.Values, nil
+}
+
+// CellString renders a cell value as text. Numbers are written in full rather
+// than in exponent form.
+func CellString(v interface{}) string {
+	switch c := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case float64:
+		return strconv.FormatFloat(c, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(c)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// EncodeValues encodes a grid of values as csv, tsv, json, ndjson (one JSON
+// array per row), md (a Markdown table whose first row is the header) or html
+// (an HTML table whose first row is the header).
+func EncodeValues(values [][]interface{}, format string) (string, error) {
+	switch format {
+	case "csv", "tsv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if format == "tsv" {
+			w.Comma = '\t'
+		}
+		for _, row := range values {
+			record := make([]string, len(row))
+			for i, cell := range row {
+				record[i] = CellString(cell)
+			}
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+		}
+		w.Flush()
+		return buf.String(), w.Error()
+	case "json":
+		if values == nil {
+			values = [][]interface{}{}
+		}
+		b, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(b) + "\n", nil
+	case "ndjson":
+		var b strings.Builder
+		for _, row := range values {
+			line, err := json.Marshal(row)
+			if err != nil {
+				return "", err
+			}
+			b.Write(line)
+			b.WriteString("\n")
+		}
+		return b.String(), nil
+	case "md", "markdown":
+		return MarkdownTable(values, TableOptions{Header: "first-row"})
+	case "html":
+		return HTMLTable(values, TableOptions{Header: "first-row"})
+	}
+	return "", fmt.Errorf("invalid format: %s. Valid formats are: csv, tsv, json, ndjson, md, html", format)
+}
+
+// SheetInfo describes a single sheet (tab) in a spreadsheet.
+type SheetInfo struct {
+	SheetID       int64  `json:"sheetId"`
+	Title         string `json:"title"`
+	Index         int64  `json:"index"`
+	Rows          int64  `json:"rows"`
+	Columns       int64  `json:"columns"`
+	FrozenRows    int64  `json:"frozenRows"`
+	FrozenColumns int64  `json:"frozenColumns"`
+	Hidden        bool   `json:"hidden"`
+}
+
+func sheetInfo(p *sheets.SheetProperties) *SheetInfo {
+	info := &SheetInfo{
+		SheetID: p.SheetId,
+		Title:   p.Title,
+		Index:   p.Index,
+		Hidden:  p.Hidden,
+	}
+	if g := p.GridProperties; g != nil {
+		info.Rows = g.RowCount
+		info.Columns = g.ColumnCount
+		info.FrozenRows = g.FrozenRowCount
+		info.FrozenColumns = g.FrozenColumnCount
+	}
+	return info
+}
+
 // ListSheets lists the sheets in a spreadsheet.
-func ListSheets(sheetsSvc *sheets.Service, spreadsheetId string) ([]string, error) {
-	spreadsheet, err := sheetsSvc.Spreadsheets.Get(spreadsheetId).Fields("sheets(properties(title))").Do()
+func ListSheets(sheetsSvc *sheets.Service, spreadsheetId string) ([]*SheetInfo, error) {
+	spreadsheet, err := sheetsSvc.Spreadsheets.Get(spreadsheetId).Fields("sheets(properties(sheetId,title,index,hidden,gridProperties))").Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve spreadsheet: %w", err)
 	}
 
-	var sheetNames []string
+	var infos []*SheetInfo
 	for _, sheet := range spreadsheet.Sheets {
-		sheetNames = append(sheetNames, sheet.Properties.Title)
+		infos = append(infos, sheetInfo(sheet.Properties))
 	}
 
-	return sheetNames, nil
+	return infos, nil
 }
 
-// GetSheetAsCSV gets a sheet as CSV.
-func GetSheetAsCSV(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string) (string, error) {
-	readRange := sheetName
-	resp, err := sheetsSvc.Spreadsheets.Values.Get(spreadsheetId, readRange).Do()
+// FindSheet returns the sheet with the given title or, failing that, the given numeric sheet ID.
+func FindSheet(sheetsSvc *sheets.Service, spreadsheetId string, titleOrId string) (*SheetInfo, error) {
+	infos, err := ListSheets(sheetsSvc, spreadsheetId)
 	if err != nil {
-		return "", fmt.Errorf("unable to retrieve data from sheet: %w", err)
+		return nil, err
 	}
+	for _, info := range infos {
+		if info.Title == titleOrId {
+			return info, nil
+		}
+	}
+	if id, err := strconv.ParseInt(titleOrId, 10, 64); err == nil {
+		for _, info := range infos {
+			if info.SheetID == id {
+				return info, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("sheet %s not found in spreadsheet %s", titleOrId, spreadsheetId)
+}
 
-	if len(resp.Values) == 0 {
-		return "", fmt.Errorf("no data found")
+// batchUpdate applies spreadsheet-level requests in a single call.
+func batchUpdate(sheetsSvc *sheets.Service, spreadsheetId string, requests ...*sheets.Request) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	return sheetsSvc.Spreadsheets.BatchUpdate(spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Do()
+}
+
+// CreateSpreadsheet creates a spreadsheet with the given tabs, or a single default tab when none are given.
+func CreateSpreadsheet(sheetsSvc *sheets.Service, title string, tabs []string) (*sheets.Spreadsheet, error) {
+	spreadsheet := &sheets.Spreadsheet{
+		Properties: &sheets.SpreadsheetProperties{Title: title},
+	}
+	for _, tab := range tabs {
+		spreadsheet.Sheets = append(spreadsheet.Sheets, &sheets.Sheet{
+			Properties: &sheets.SheetProperties{Title: tab},
+		})
+	}
+	res, err := sheetsSvc.Spreadsheets.Create(spreadsheet).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create spreadsheet: %w", err)
 	}
+	return res, nil
+}
 
-	var csvBuilder strings.Builder
-	for _, row := range resp.Values {
-		var csvRow []string
-		for _, cell := range row {
-			csvRow = append(csvRow, fmt.Sprintf("%v", cell))
-		}
-		csvBuilder.WriteString(strings.Join(csvRow, ","))
-		csvBuilder.WriteString("\n")
+// RenameSheet changes the title of a sheet.
+func RenameSheet(sheetsSvc *sheets.Service, spreadsheetId string, sheetId int64, title string) error {
+	_, err := batchUpdate(sheetsSvc, spreadsheetId, &sheets.Request{
+		UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+			Properties: &sheets.SheetProperties{SheetId: sheetId, Title: title},
+			Fields:     "title",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to rename sheet %d: %w", sheetId, err)
 	}
+	return nil
+}
 
-	return csvBuilder.String(), nil
+// DeleteSheet removes a sheet and all of its data.
+func DeleteSheet(sheetsSvc *sheets.Service, spreadsheetId string, sheetId int64) error {
+	_, err := batchUpdate(sheetsSvc, spreadsheetId, &sheets.Request{
+		DeleteSheet: &sheets.DeleteSheetRequest{SheetId: sheetId},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete sheet %d: %w", sheetId, err)
+	}
+	return nil
 }
 
-// GetSheetRange gets a specific range from a sheet.
-func GetSheetRange(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, sheetRange string) ([][]interface{}, error) {
-	readRange := sheetName + "!" + sheetRange
-	resp, err := sheetsSvc.Spreadsheets.Values.Get(spreadsheetId, readRange).Do()
+// DuplicateSheet copies a sheet within the same spreadsheet. An empty title
+// lets Sheets choose one, such as "Copy of Sheet1".
+func DuplicateSheet(sheetsSvc *sheets.Service, spreadsheetId string, sheetId int64, title string) (*SheetInfo, error) {
+	resp, err := batchUpdate(sheetsSvc, spreadsheetId, &sheets.Request{
+		DuplicateSheet: &sheets.DuplicateSheetRequest{
+			SourceSheetId: sheetId,
+			NewSheetName:  title,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve data from sheet: %w", err)
+		return nil, fmt.Errorf("unable to duplicate sheet %d: %w", sheetId, err)
 	}
+	return sheetInfo(resp.Replies[0].DuplicateSheet.Properties), nil
+}
 
-	return resp.Values, nil
+// SheetRange returns the A1 reference to a range on a sheet. The range may
+// name a sheet of its own; when it is empty, the reference covers the whole sheet.
+func SheetRange(sheetName string, sheetRange string) (string, error) {
+	if sheetRange == "" {
+		return a1.Sheet(sheetName).String(), nil
+	}
+	r, err := a1.ParseRange(sheetRange)
+	if err != nil {
+		return "", err
+	}
+	return r.Qualify(sheetName).String(), nil
+}
+
+// GetSheetRange gets a specific range from a sheet, or the whole sheet when
+// sheetRange is empty.
+func GetSheetRange(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, sheetRange string, opts ValueRenderOptions) ([][]interface{}, error) {
+	readRange, err := SheetRange(sheetName, sheetRange)
+	if err != nil {
+		return nil, err
+	}
+	return GetSheetValues(sheetsSvc, spreadsheetId, readRange, opts)
 }
 
 // UpdateSheetRange updates a specific range in a sheet.
 func UpdateSheetRange(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, sheetRange string, values [][]interface{}) error {
-	writeRange := sheetName + "!" + sheetRange
+	writeRange, err := SheetRange(sheetName, sheetRange)
+	if err != nil {
+		return err
+	}
 	valueRange := &sheets.ValueRange{
 		Values: values,
 	}
-	_, err := sheetsSvc.Spreadsheets.Values.Update(spreadsheetId, writeRange, valueRange).ValueInputOption("USER_ENTERED").Do()
+	_, err = sheetsSvc.Spreadsheets.Values.Update(spreadsheetId, writeRange, valueRange).ValueInputOption("USER_ENTERED").Do()
 	if err != nil {
 		return fmt.Errorf("unable to update sheet: %w", err)
 	}
 	return nil
 }
+
+// AddSheet adds a new tab to a spreadsheet.
+func AddSheet(sheetsSvc *sheets.Service, spreadsheetId string, title string) (*SheetInfo, error) {
+	resp, err := batchUpdate(sheetsSvc, spreadsheetId, &sheets.Request{
+		AddSheet: &sheets.AddSheetRequest{
+			Properties: &sheets.SheetProperties{Title: title},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to add sheet %s: %w", title, err)
+	}
+	return sheetInfo(resp.Replies[0].AddSheet.Properties), nil
+}
+
+// ClearSheetRange clears the values, but not the formatting, in an A1 range.
+func ClearSheetRange(sheetsSvc *sheets.Service, spreadsheetId string, clearRange string) error {
+	_, err := sheetsSvc.Spreadsheets.Values.Clear(spreadsheetId, clearRange, &sheets.ClearValuesRequest{}).Do()
+	if err != nil {
+		return fmt.Errorf("unable to clear %s: %w", clearRange, err)
+	}
+	return nil
+}
+
+// valueInputOption maps an input name to a Sheets ValueInputOption.
+func valueInputOption(input string) (string, error) {
+	switch input {
+	case "", "user-entered":
+		return "USER_ENTERED", nil
+	case "raw":
+		return "RAW", nil
+	}
+	return "", fmt.Errorf("invalid input option: %s. Valid options are: raw, user-entered", input)
+}
+
+// ImportOptions controls how ImportValues writes a grid.
+type ImportOptions struct {
+	// Anchor is the top-left cell to write from, such as B2. Defaults to A1.
+	Anchor string
+	// Clear clears every value on the sheet before writing.
+	Clear bool
+	// Create adds the sheet if it does not exist.
+	Create bool
+	// Input is "user-entered" (the default), which parses values as if typed
+	// into the UI, or "raw", which stores them as-is.
+	Input string
+	// ChunkRows is the maximum number of rows written per request. Defaults to 1000.
+	ChunkRows int
+}
+
+// ImportResult summarises an import.
+type ImportResult struct {
+	SpreadsheetID string   `json:"spreadsheetId"`
+	Sheet         string   `json:"sheet"`
+	CreatedSheet  bool     `json:"createdSheet"`
+	Cleared       bool     `json:"cleared"`
+	UpdatedRanges []string `json:"updatedRanges"`
+	UpdatedRows   int64    `json:"updatedRows"`
+	UpdatedCells  int64    `json:"updatedCells"`
+}
+
+// ImportValues writes a grid of values to a sheet starting at an anchor cell,
+// splitting large grids into several requests.
+func ImportValues(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, values [][]interface{}, opts ImportOptions) (*ImportResult, error) {
+	input, err := valueInputOption(opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	anchor := opts.Anchor
+	if anchor == "" {
+		anchor = "A1"
+	}
+	start, err := a1.ParseCell(anchor)
+	if err != nil {
+		return nil, err
+	}
+	chunkRows := opts.ChunkRows
+	if chunkRows <= 0 {
+		chunkRows = 1000
+	}
+
+	result := &ImportResult{SpreadsheetID: spreadsheetId, Sheet: sheetName, UpdatedRanges: []string{}}
+	if opts.Create {
+		infos, err := ListSheets(sheetsSvc, spreadsheetId)
+		if err != nil {
+			return nil, err
+		}
+		exists := slices.ContainsFunc(infos, func(info *SheetInfo) bool { return info.Title == sheetName })
+		if !exists {
+			if _, err := AddSheet(sheetsSvc, spreadsheetId, sheetName); err != nil {
+				return nil, err
+			}
+			result.CreatedSheet = true
+		}
+	}
+	if opts.Clear && !result.CreatedSheet {
+		if err := ClearSheetRange(sheetsSvc, spreadsheetId, a1.Sheet(sheetName).String()); err != nil {
+			return nil, err
+		}
+		result.Cleared = true
+	}
+
+	for first := 0; first < len(values); first += chunkRows {
+		end := min(first+chunkRows, len(values))
+		chunk := values[first:end]
+		writeRange := a1.Covering(sheetName, a1.Cell{Col: start.Col, Row: start.Row + first}, chunk).String()
+		resp, err := sheetsSvc.Spreadsheets.Values.Update(spreadsheetId, writeRange, &sheets.ValueRange{
+			Values: chunk,
+		}).ValueInputOption(input).Do()
+		if err != nil {
+			return result, fmt.Errorf("unable to write rows %d-%d: %w", first+1, end, err)
+		}
+		result.UpdatedRanges = append(result.UpdatedRanges, resp.UpdatedRange)
+		result.UpdatedRows += resp.UpdatedRows
+		result.UpdatedCells += resp.UpdatedCells
+	}
+	return result, nil
+}
+
+// AppendResult summarises an append.
+type AppendResult struct {
+	SpreadsheetID string   `json:"spreadsheetId"`
+	Sheet         string   `json:"sheet"`
+	AddedColumns  []string `json:"addedColumns"`
+	UpdatedRange  string   `json:"updatedRange"`
+	UpdatedRows   int64    `json:"updatedRows"`
+	UpdatedCells  int64    `json:"updatedCells"`
+}
+
+// AppendRecords appends records as new rows below the table on a sheet,
+// placing each value in the column whose header in row 1 matches its key.
+// Keys without a column are added to the end of the header row first.
+func AppendRecords(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, records []*Record, input string) (*AppendResult, error) {
+	inputOption, err := valueInputOption(input)
+	if err != nil {
+		return nil, err
+	}
+
+	headerRange := a1.Range{Sheet: sheetName, EndRow: 1}
+	headerValues, err := GetSheetValues(sheetsSvc, spreadsheetId, headerRange.String(), ValueRenderOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var header []string
+	if len(headerValues) > 0 {
+		for _, cell := range headerValues[0] {
+			header = append(header, CellString(cell))
+		}
+	}
+
+	result := &AppendResult{SpreadsheetID: spreadsheetId, Sheet: sheetName, AddedColumns: []string{}}
+	for _, rec := range records {
+		for _, k := range rec.Keys {
+			if !slices.Contains(header, k) {
+				header = append(header, k)
+				result.AddedColumns = append(result.AddedColumns, k)
+			}
+		}
+	}
+
+	if len(result.AddedColumns) > 0 {
+		first := len(header) - len(result.AddedColumns)
+		row := make([]interface{}, len(result.AddedColumns))
+		for i, k := range result.AddedColumns {
+			row[i] = k
+		}
+		added := [][]interface{}{row}
+		writeRange := a1.Covering(sheetName, a1.Cell{Col: first}, added).String()
+		_, err := sheetsSvc.Spreadsheets.Values.Update(spreadsheetId, writeRange, &sheets.ValueRange{
+			Values: added,
+		}).ValueInputOption("RAW").Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to add header columns: %w", err)
+		}
+	}
+
+	rows := make([][]interface{}, len(records))
+	for i, rec := range records {
+		row := make([]interface{}, len(header))
+		for j, k := range header {
+			if v, ok := rec.Values[k]; ok {
+				row[j] = v
+			} else {
+				row[j] = ""
+			}
+		}
+		rows[i] = row
+	}
+
+	resp, err := sheetsSvc.Spreadsheets.Values.Append(spreadsheetId, a1.Cell{}.In(sheetName).String(), &sheets.ValueRange{
+		Values: rows,
+	}).ValueInputOption(inputOption).InsertDataOption("INSERT_ROWS").Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to append rows: %w", err)
+	}
+	if resp.Updates != nil {
+		result.UpdatedRange = resp.Updates.UpdatedRange
+		result.UpdatedRows = resp.Updates.UpdatedRows
+		result.UpdatedCells = resp.Updates.UpdatedCells
+	}
+	return result, nil
+}
+
+// BatchGetValues reads several A1 ranges, possibly on different sheets, in one
+// request. The results are in the same order as the ranges.
+func BatchGetValues(sheetsSvc *sheets.Service, spreadsheetId string, ranges []string, opts ValueRenderOptions) ([]*sheets.ValueRange, error) {
+	render, err := valueRenderOption(opts.Render)
+	if err != nil {
+		return nil, err
+	}
+	dateTimeRender, err := dateTimeRenderOption(opts.DateTimeRender)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sheetsSvc.Spreadsheets.Values.BatchGet(spreadsheetId).Ranges(ranges...).
+		ValueRenderOption(render).DateTimeRenderOption(dateTimeRender).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve ranges: %w", err)
+	}
+	return resp.ValueRanges, nil
+}
+
+// BatchUpdateValues writes values to several A1 ranges in one request. The
+// responses are in the order of the sorted ranges.
+func BatchUpdateValues(sheetsSvc *sheets.Service, spreadsheetId string, data map[string][][]interface{}, input string) ([]*sheets.UpdateValuesResponse, error) {
+	inputOption, err := valueInputOption(input)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]string, 0, len(data))
+	for rng := range data {
+		ranges = append(ranges, rng)
+	}
+	slices.Sort(ranges)
+
+	req := &sheets.BatchUpdateValuesRequest{ValueInputOption: inputOption}
+	for _, rng := range ranges {
+		req.Data = append(req.Data, &sheets.ValueRange{Range: rng, Values: data[rng]})
+	}
+	resp, err := sheetsSvc.Spreadsheets.Values.BatchUpdate(spreadsheetId, req).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to update ranges: %w", err)
+	}
+	return resp.Responses, nil
+}
@@ -0,0 +1,401 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghchinoy/drivectl/internal/a1"
+)
+
+// ReadCSVGrid reads delimited text into a grid of values. Rows may have
+// different lengths.
+func ReadCSVGrid(r io.Reader, comma rune) ([][]interface{}, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse delimited data: %w", err)
+	}
+	values := make([][]interface{}, len(records))
+	for i, record := range records {
+		row := make([]interface{}, len(record))
+		for j, field := range record {
+			row[j] = field
+		}
+		values[i] = row
+	}
+	return values, nil
+}
+
+// ReadJSONGrid reads a JSON array of arrays, or an array of objects, into a
+// grid of values. For objects, the first row is a header made of every key in
+// the order it first appears.
+func ReadJSONGrid(r io.Reader) ([][]interface{}, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var items []json.RawMessage
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON array: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(items[0]), []byte("[")) {
+		values := make([][]interface{}, len(items))
+		for i, item := range items {
+			if err := decodeJSONNumbers(item, &values[i]); err != nil {
+				return nil, fmt.Errorf("unable to parse row %d: %w", i+1, err)
+			}
+		}
+		return values, nil
+	}
+
+	records := make([]*Record, len(items))
+	for i, item := range items {
+		rec, err := ParseRecord(item)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse object %d: %w", i+1, err)
+		}
+		records[i] = rec
+	}
+	return RecordsToGrid(records), nil
+}
+
+// ScanNDJSONRecords reads one JSON object per line, skipping blank lines, and
+// calls fn with each as soon as it is read, so that a stream that stays open
+// can be processed as it arrives.
+func ScanNDJSONRecords(r io.Reader, fn func(rec *Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		rec, err := ParseRecord([]byte(text))
+		if err != nil {
+			return fmt.Errorf("unable to parse line %d: %w", line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read records: %w", err)
+	}
+	return nil
+}
+
+// Record is a JSON object whose keys keep the order they were written in.
+type Record struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// ParseRecord decodes a JSON object into a Record.
+func ParseRecord(data []byte) (*Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	rec := &Record{Values: make(map[string]interface{})}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		if _, seen := rec.Values[key]; !seen {
+			rec.Keys = append(rec.Keys, key)
+		}
+		rec.Values[key] = gridValue(v)
+	}
+	return rec, nil
+}
+
+// RecordsToGrid lays records out as rows under a header of every key, in the
+// order each key first appears.
+func RecordsToGrid(records []*Record) [][]interface{} {
+	var header []string
+	seen := make(map[string]bool)
+	for _, rec := range records {
+		for _, k := range rec.Keys {
+			if !seen[k] {
+				seen[k] = true
+				header = append(header, k)
+			}
+		}
+	}
+
+	values := make([][]interface{}, 0, len(records)+1)
+	headerRow := make([]interface{}, len(header))
+	for i, k := range header {
+		headerRow[i] = k
+	}
+	values = append(values, headerRow)
+	for _, rec := range records {
+		row := make([]interface{}, len(header))
+		for i, k := range header {
+			if v, ok := rec.Values[k]; ok {
+				row[i] = v
+			} else {
+				row[i] = ""
+			}
+		}
+		values = append(values, row)
+	}
+	return values
+}
+
+func decodeJSONNumbers(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if row, ok := v.(*[]interface{}); ok {
+		for i, cell := range *row {
+			(*row)[i] = gridValue(cell)
+		}
+	}
+	return nil
+}
+
+// gridValue converts a decoded JSON value into something a cell can hold.
+// Nested arrays and objects are written as JSON text.
+func gridValue(v interface{}) interface{} {
+	switch v.(type) {
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return v
+}
+
+// MarshalJSON encodes a record as a JSON object with its keys in order.
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range r.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.Values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// RecordOptions controls how ValuesToRecords turns rows into records.
+type RecordOptions struct {
+	// HeaderRow is the 1-based row, within the values, that holds the keys. Defaults to 1.
+	HeaderRow int
+	// InferTypes converts text that looks like a number, boolean or date into
+	// that type, and empty cells into null.
+	InferTypes bool
+	// KeepBlankRows keeps rows with no values instead of dropping them.
+	KeepBlankRows bool
+}
+
+// ValuesToRecords converts the rows below a header row into records keyed by
+// the header. Empty headers are named after their column letter, and repeated
+// headers get a numeric suffix.
+func ValuesToRecords(values [][]interface{}, opts RecordOptions) ([]*Record, error) {
+	headerRow := opts.HeaderRow
+	if headerRow == 0 {
+		headerRow = 1
+	}
+	if headerRow < 1 {
+		return nil, fmt.Errorf("invalid header row: %d", headerRow)
+	}
+	records := []*Record{}
+	if len(values) < headerRow {
+		return records, nil
+	}
+
+	width := 0
+	for _, row := range values[headerRow-1:] {
+		width = max(width, len(row))
+	}
+	keys := make([]string, width)
+	seen := make(map[string]int)
+	for i := range keys {
+		key := ""
+		if i < len(values[headerRow-1]) {
+			key = strings.TrimSpace(CellString(values[headerRow-1][i]))
+		}
+		if key == "" {
+			key = a1.ColumnName(i)
+		}
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s_%d", key, n)
+		}
+		keys[i] = key
+	}
+
+	for _, row := range values[headerRow:] {
+		if !opts.KeepBlankRows && blankRow(row) {
+			continue
+		}
+		rec := &Record{Keys: keys, Values: make(map[string]interface{}, len(keys))}
+		for i, k := range keys {
+			var v interface{} = ""
+			if i < len(row) {
+				v = row[i]
+			}
+			if opts.InferTypes {
+				v = inferType(v)
+			}
+			rec.Values[k] = v
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func blankRow(row []interface{}) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(CellString(cell)) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// numberPattern matches plain decimal numbers, optionally with thousands separators.
+var numberPattern = regexp.MustCompile(`^-?(\d+|\d{1,3}(,\d{3})+)(\.\d+)?$`)
+
+// recordDateLayouts are the date formats inferType recognises, and the layout
+// each is written back out in.
+var recordDateLayouts = []struct{ in, out string }{
+	{time.RFC3339, time.RFC3339},
+	{"2006-01-02 15:04:05", "2006-01-02T15:04:05"},
+	{"2006-01-02", "2006-01-02"},
+	{"1/2/2006 15:04:05", "2006-01-02T15:04:05"},
+	{"1/2/2006", "2006-01-02"},
+}
+
+// inferType converts a text cell into a number, boolean, ISO 8601 date or
+// null when it unambiguously looks like one. Numbers with leading zeros, such
+// as postal codes, are left as text.
+func inferType(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if numberPattern.MatchString(s) && !hasLeadingZero(s) {
+		if n, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64); err == nil {
+			return n
+		}
+	}
+	for _, layout := range recordDateLayouts {
+		if t, err := time.Parse(layout.in, s); err == nil {
+			return t.Format(layout.out)
+		}
+	}
+	return s
+}
+
+func hasLeadingZero(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return len(s) > 1 && s[0] == '0' && s[1] != '.'
+}
+
+// ReadRangeValues reads a JSON object that maps A1 ranges to 2D arrays of
+// values. A scalar is accepted as shorthand for a single cell, and a flat
+// array for a single row.
+func ReadRangeValues(r io.Reader) (map[string][][]interface{}, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON object of ranges: %w", err)
+	}
+	data := make(map[string][][]interface{}, len(raw))
+	for rng, msg := range raw {
+		var v interface{}
+		if err := decodeJSONNumbers(msg, &v); err != nil {
+			return nil, fmt.Errorf("unable to parse values for %s: %w", rng, err)
+		}
+		rows, ok := v.([]interface{})
+		if !ok {
+			data[rng] = [][]interface{}{{gridValue(v)}}
+			continue
+		}
+		var grid [][]interface{}
+		for _, row := range rows {
+			cells, ok := row.([]interface{})
+			if !ok {
+				// A flat array is a single row.
+				grid = [][]interface{}{rowValues(rows)}
+				break
+			}
+			grid = append(grid, rowValues(cells))
+		}
+		data[rng] = grid
+	}
+	return data, nil
+}
+
+func rowValues(cells []interface{}) []interface{} {
+	row := make([]interface{}, len(cells))
+	for i, cell := range cells {
+		row[i] = gridValue(cell)
+	}
+	return row
+}
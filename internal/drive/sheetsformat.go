@@ -0,0 +1,472 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ghchinoy/drivectl/internal/a1"
+	"google.golang.org/api/sheets/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatSpec describes how to style a sheet. It is read from YAML or JSON by
+// ParseFormatSpec and turned into batchUpdate requests by FormatSheet.
+type FormatSpec struct {
+	// HeaderRows is the number of header rows. Defaults to 1.
+	HeaderRows *int `yaml:"headerRows"`
+	// Header styles the header rows.
+	Header *CellStyle `yaml:"header"`
+	// Freeze sets the number of frozen rows and columns.
+	Freeze *FreezeSpec `yaml:"freeze"`
+	// AutoResize fits every column to its contents.
+	AutoResize bool `yaml:"autoResize"`
+	// Columns styles whole columns below the header rows.
+	Columns []ColumnFormat `yaml:"columns"`
+	// Ranges styles arbitrary A1 ranges.
+	Ranges []RangeFormat `yaml:"ranges"`
+	// ReplaceConditional deletes the sheet's existing conditional format
+	// rules before adding the ones in Conditional.
+	ReplaceConditional bool `yaml:"replaceConditional"`
+	// Conditional adds conditional format rules.
+	Conditional []ConditionalFormat `yaml:"conditional"`
+}
+
+// CellStyle is a set of cell formatting options. Unset options are left as they are.
+type CellStyle struct {
+	Bold       *bool  `yaml:"bold"`
+	Italic     *bool  `yaml:"italic"`
+	Color      string `yaml:"color"`
+	Background string `yaml:"background"`
+	// Align is left, center or right.
+	Align string `yaml:"align"`
+	// NumberFormat is a pattern such as "#,##0.00", or one of number, percent,
+	// currency, date, time, datetime, scientific or text.
+	NumberFormat string `yaml:"numberFormat"`
+	// DateFormat is a date pattern such as "yyyy-mm-dd".
+	DateFormat string `yaml:"dateFormat"`
+}
+
+// FreezeSpec sets the frozen rows and columns; zero unfreezes them.
+type FreezeSpec struct {
+	Rows    *int `yaml:"rows"`
+	Columns *int `yaml:"columns"`
+}
+
+// ColumnFormat styles columns, given either as an A1 column range such as
+// "B" or "B:D", or by the name in the header row.
+type ColumnFormat struct {
+	Range      string `yaml:"range"`
+	Name       string `yaml:"name"`
+	Width      int    `yaml:"width"`
+	AutoResize bool   `yaml:"autoResize"`
+	CellStyle  `yaml:",inline"`
+}
+
+// RangeFormat styles an A1 range within the sheet, such as "A2:D10".
+type RangeFormat struct {
+	Range     string `yaml:"range"`
+	CellStyle `yaml:",inline"`
+}
+
+// ConditionalFormat is a conditional format rule that styles the cells in
+// Range that meet the condition. Values holds the condition's operands, or
+// the formula for the formula condition.
+type ConditionalFormat struct {
+	Range string `yaml:"range"`
+	// Condition is one of gt, gte, lt, lte, eq, ne, between, not-between,
+	// contains, not-contains, starts-with, ends-with, text-eq, blank,
+	// not-blank or formula.
+	Condition string   `yaml:"condition"`
+	Values    []string `yaml:"values"`
+	CellStyle `yaml:",inline"`
+}
+
+// conditionTypes maps condition names to Sheets BooleanCondition types.
+var conditionTypes = map[string]string{
+	"gt":           "NUMBER_GREATER",
+	"gte":          "NUMBER_GREATER_THAN_EQ",
+	"lt":           "NUMBER_LESS",
+	"lte":          "NUMBER_LESS_THAN_EQ",
+	"eq":           "NUMBER_EQ",
+	"ne":           "NUMBER_NOT_EQ",
+	"between":      "NUMBER_BETWEEN",
+	"not-between":  "NUMBER_NOT_BETWEEN",
+	"contains":     "TEXT_CONTAINS",
+	"not-contains": "TEXT_NOT_CONTAINS",
+	"starts-with":  "TEXT_STARTS_WITH",
+	"ends-with":    "TEXT_ENDS_WITH",
+	"text-eq":      "TEXT_EQ",
+	"blank":        "BLANK",
+	"not-blank":    "NOT_BLANK",
+	"formula":      "CUSTOM_FORMULA",
+}
+
+// numberFormatTypes maps number format names to Sheets NumberFormat types.
+var numberFormatTypes = map[string]string{
+	"number":     "NUMBER",
+	"percent":    "PERCENT",
+	"currency":   "CURRENCY",
+	"date":       "DATE",
+	"time":       "TIME",
+	"datetime":   "DATE_TIME",
+	"scientific": "SCIENTIFIC",
+	"text":       "TEXT",
+}
+
+// ParseFormatSpec reads a format spec from YAML or JSON. Unknown keys are an
+// error, so that typos do not silently do nothing.
+func ParseFormatSpec(r io.Reader) (*FormatSpec, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	spec := &FormatSpec{}
+	if err := dec.Decode(spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to parse format spec: %w", err)
+	}
+	return spec, nil
+}
+
+// FormatSheet translates a format spec into batchUpdate requests for a sheet
+// and, unless dryRun is set, applies them in a single call. It returns the
+// requests.
+func FormatSheet(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, spec *FormatSpec, dryRun bool) ([]*sheets.Request, error) {
+	info, err := FindSheet(sheetsSvc, spreadsheetId, sheetName)
+	if err != nil {
+		return nil, err
+	}
+	sheetId := info.SheetID
+	headerRows := int64(1)
+	if spec.HeaderRows != nil {
+		if *spec.HeaderRows < 0 {
+			return nil, fmt.Errorf("invalid headerRows: %d", *spec.HeaderRows)
+		}
+		headerRows = int64(*spec.HeaderRows)
+	}
+
+	var reqs []*sheets.Request
+	if spec.Header != nil && headerRows > 0 {
+		req, err := repeatCell(&sheets.GridRange{SheetId: sheetId, EndRowIndex: headerRows}, spec.Header)
+		if err != nil {
+			return nil, fmt.Errorf("header: %w", err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	if spec.Freeze != nil {
+		props := &sheets.SheetProperties{SheetId: sheetId, GridProperties: &sheets.GridProperties{}}
+		var fields []string
+		if spec.Freeze.Rows != nil {
+			props.GridProperties.FrozenRowCount = int64(*spec.Freeze.Rows)
+			props.GridProperties.ForceSendFields = append(props.GridProperties.ForceSendFields, "FrozenRowCount")
+			fields = append(fields, "gridProperties.frozenRowCount")
+		}
+		if spec.Freeze.Columns != nil {
+			props.GridProperties.FrozenColumnCount = int64(*spec.Freeze.Columns)
+			props.GridProperties.ForceSendFields = append(props.GridProperties.ForceSendFields, "FrozenColumnCount")
+			fields = append(fields, "gridProperties.frozenColumnCount")
+		}
+		if len(fields) > 0 {
+			reqs = append(reqs, &sheets.Request{
+				UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+					Properties: props,
+					Fields:     strings.Join(fields, ","),
+				},
+			})
+		}
+	}
+
+	var header []string
+	for i, col := range spec.Columns {
+		if (col.Range == "") == (col.Name == "") {
+			return nil, fmt.Errorf("columns[%d]: set exactly one of range or name", i)
+		}
+		var rng *sheets.GridRange
+		if col.Name != "" {
+			if header == nil {
+				if header, err = headerNames(sheetsSvc, spreadsheetId, sheetName, headerRows); err != nil {
+					return nil, err
+				}
+			}
+			c := slices.Index(header, col.Name)
+			if c < 0 {
+				return nil, fmt.Errorf("columns[%d]: no column named %q (columns: %s)", i, col.Name, strings.Join(header, ", "))
+			}
+			rng = &sheets.GridRange{SheetId: sheetId, StartColumnIndex: int64(c), EndColumnIndex: int64(c + 1)}
+		} else {
+			rng, err = gridRange(sheetId, col.Range)
+			if err != nil || rng.StartRowIndex != 0 || rng.EndRowIndex != 0 || rng.EndColumnIndex == 0 {
+				return nil, fmt.Errorf("columns[%d]: invalid column range %q", i, col.Range)
+			}
+		}
+		dims := &sheets.DimensionRange{SheetId: sheetId, Dimension: "COLUMNS", StartIndex: rng.StartColumnIndex, EndIndex: rng.EndColumnIndex}
+		if col.Width > 0 {
+			reqs = append(reqs, &sheets.Request{
+				UpdateDimensionProperties: &sheets.UpdateDimensionPropertiesRequest{
+					Range:      dims,
+					Properties: &sheets.DimensionProperties{PixelSize: int64(col.Width)},
+					Fields:     "pixelSize",
+				},
+			})
+		}
+		if col.AutoResize {
+			reqs = append(reqs, &sheets.Request{AutoResizeDimensions: &sheets.AutoResizeDimensionsRequest{Dimensions: dims}})
+		}
+		if !col.CellStyle.empty() {
+			rng.StartRowIndex = headerRows
+			req, err := repeatCell(rng, &col.CellStyle)
+			if err != nil {
+				return nil, fmt.Errorf("columns[%d]: %w", i, err)
+			}
+			reqs = append(reqs, req)
+		}
+	}
+
+	for i, r := range spec.Ranges {
+		rng, err := gridRange(sheetId, r.Range)
+		if err != nil {
+			return nil, fmt.Errorf("ranges[%d]: %w", i, err)
+		}
+		req, err := repeatCell(rng, &r.CellStyle)
+		if err != nil {
+			return nil, fmt.Errorf("ranges[%d]: %w", i, err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	if spec.ReplaceConditional {
+		existing, err := sheetsSvc.Spreadsheets.Get(spreadsheetId).Fields("sheets(properties.sheetId,conditionalFormats)").Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve conditional formats: %w", err)
+		}
+		for _, s := range existing.Sheets {
+			if s.Properties == nil || s.Properties.SheetId != sheetId {
+				continue
+			}
+			// Delete from the end so the remaining indexes do not shift.
+			for j := len(s.ConditionalFormats) - 1; j >= 0; j-- {
+				reqs = append(reqs, &sheets.Request{
+					DeleteConditionalFormatRule: &sheets.DeleteConditionalFormatRuleRequest{SheetId: sheetId, Index: int64(j)},
+				})
+			}
+		}
+	}
+	for i, c := range spec.Conditional {
+		req, err := conditionalRule(sheetId, c, i)
+		if err != nil {
+			return nil, fmt.Errorf("conditional[%d]: %w", i, err)
+		}
+		reqs = append(reqs, req)
+	}
+
+	// Resize last, so the widths reflect the new number formats.
+	if spec.AutoResize {
+		reqs = append(reqs, &sheets.Request{
+			AutoResizeDimensions: &sheets.AutoResizeDimensionsRequest{
+				Dimensions: &sheets.DimensionRange{SheetId: sheetId, Dimension: "COLUMNS"},
+			},
+		})
+	}
+
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("the format spec has nothing to apply")
+	}
+	if !dryRun {
+		if _, err := batchUpdate(sheetsSvc, spreadsheetId, reqs...); err != nil {
+			return nil, fmt.Errorf("unable to format sheet %s: %w", sheetName, err)
+		}
+	}
+	return reqs, nil
+}
+
+// headerNames reads the last header row of a sheet.
+func headerNames(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, headerRows int64) ([]string, error) {
+	row := int(max(headerRows, 1))
+	headerRange := a1.Range{Sheet: sheetName, StartRow: row - 1, EndRow: row}
+	values, err := GetSheetValues(sheetsSvc, spreadsheetId, headerRange.String(), ValueRenderOptions{})
+	if err != nil {
+		return nil, err
+	}
+	header := []string{}
+	if len(values) > 0 {
+		for _, cell := range values[0] {
+			header = append(header, strings.TrimSpace(CellString(cell)))
+		}
+	}
+	return header, nil
+}
+
+func (s *CellStyle) empty() bool {
+	return *s == CellStyle{}
+}
+
+// cellFormat converts a style into a CellFormat and the field mask naming the
+// options it sets.
+func (s *CellStyle) cellFormat() (*sheets.CellFormat, []string, error) {
+	f := &sheets.CellFormat{}
+	var fields []string
+	if s.Bold != nil || s.Italic != nil || s.Color != "" {
+		f.TextFormat = &sheets.TextFormat{}
+	}
+	if s.Bold != nil {
+		f.TextFormat.Bold = *s.Bold
+		f.TextFormat.ForceSendFields = append(f.TextFormat.ForceSendFields, "Bold")
+		fields = append(fields, "textFormat.bold")
+	}
+	if s.Italic != nil {
+		f.TextFormat.Italic = *s.Italic
+		f.TextFormat.ForceSendFields = append(f.TextFormat.ForceSendFields, "Italic")
+		fields = append(fields, "textFormat.italic")
+	}
+	if s.Color != "" {
+		c, err := parseColor(s.Color)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.TextFormat.ForegroundColor = c
+		fields = append(fields, "textFormat.foregroundColor")
+	}
+	if s.Background != "" {
+		c, err := parseColor(s.Background)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.BackgroundColor = c
+		fields = append(fields, "backgroundColor")
+	}
+	if s.Align != "" {
+		switch strings.ToLower(s.Align) {
+		case "left", "center", "right":
+			f.HorizontalAlignment = strings.ToUpper(s.Align)
+		default:
+			return nil, nil, fmt.Errorf("invalid align: %s. Valid values are: left, center, right", s.Align)
+		}
+		fields = append(fields, "horizontalAlignment")
+	}
+	switch {
+	case s.NumberFormat != "" && s.DateFormat != "":
+		return nil, nil, fmt.Errorf("set only one of numberFormat and dateFormat")
+	case s.DateFormat != "":
+		f.NumberFormat = &sheets.NumberFormat{Type: "DATE", Pattern: s.DateFormat}
+		fields = append(fields, "numberFormat")
+	case s.NumberFormat != "":
+		if t, ok := numberFormatTypes[strings.ToLower(s.NumberFormat)]; ok {
+			f.NumberFormat = &sheets.NumberFormat{Type: t}
+		} else {
+			f.NumberFormat = &sheets.NumberFormat{Type: "NUMBER", Pattern: s.NumberFormat}
+		}
+		fields = append(fields, "numberFormat")
+	}
+	return f, fields, nil
+}
+
+// repeatCell builds a request that applies a style to every cell in a range.
+func repeatCell(rng *sheets.GridRange, s *CellStyle) (*sheets.Request, error) {
+	format, fields, err := s.cellFormat()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no formatting options set")
+	}
+	for i, f := range fields {
+		fields[i] = "userEnteredFormat." + f
+	}
+	return &sheets.Request{
+		RepeatCell: &sheets.RepeatCellRequest{
+			Range:  rng,
+			Cell:   &sheets.CellData{UserEnteredFormat: format},
+			Fields: strings.Join(fields, ","),
+		},
+	}, nil
+}
+
+// conditionalRule builds a request that adds a conditional format rule at the given index.
+func conditionalRule(sheetId int64, c ConditionalFormat, index int) (*sheets.Request, error) {
+	t, ok := conditionTypes[strings.ToLower(c.Condition)]
+	if !ok {
+		names := make([]string, 0, len(conditionTypes))
+		for name := range conditionTypes {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("invalid condition: %q. Valid conditions are: %s", c.Condition, strings.Join(names, ", "))
+	}
+	rng, err := gridRange(sheetId, c.Range)
+	if err != nil {
+		return nil, err
+	}
+	if c.NumberFormat != "" || c.DateFormat != "" || c.Align != "" {
+		return nil, fmt.Errorf("conditional formats support only bold, italic, color and background")
+	}
+	format, fields, err := c.CellStyle.cellFormat()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no formatting options set")
+	}
+	condition := &sheets.BooleanCondition{Type: t}
+	for _, v := range c.Values {
+		condition.Values = append(condition.Values, &sheets.ConditionValue{UserEnteredValue: v})
+	}
+	return &sheets.Request{
+		AddConditionalFormatRule: &sheets.AddConditionalFormatRuleRequest{
+			Index: int64(index),
+			Rule: &sheets.ConditionalFormatRule{
+				Ranges:      []*sheets.GridRange{rng},
+				BooleanRule: &sheets.BooleanRule{Condition: condition, Format: format},
+			},
+		},
+	}, nil
+}
+
+// parseColor parses a #rrggbb or #rgb colour.
+func parseColor(s string) (*sheets.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return nil, fmt.Errorf("invalid colour: %q. Use #rrggbb", s)
+	}
+	return &sheets.Color{
+		Red:             float64(n>>16&0xff) / 255,
+		Green:           float64(n>>8&0xff) / 255,
+		Blue:            float64(n&0xff) / 255,
+		ForceSendFields: []string{"Red", "Green", "Blue"},
+	}, nil
+}
+
+// gridRange converts an A1 range within a sheet, such as A1:C5, B:D, 2:5 or
+// A2:C, into a GridRange. A single column such as B is also accepted.
+func gridRange(sheetId int64, ref string) (*sheets.GridRange, error) {
+	if _, err := a1.ColumnIndex(ref); err == nil {
+		ref += ":" + ref
+	}
+	r, err := a1.ParseRange(ref)
+	if err != nil {
+		return nil, err
+	}
+	if r.Sheet != "" {
+		return nil, fmt.Errorf("invalid range: %q: the range must not name a sheet", ref)
+	}
+	return r.GridRange(sheetId), nil
+}
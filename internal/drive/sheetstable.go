@@ -0,0 +1,210 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/ghchinoy/drivectl/internal/a1"
+)
+
+// TableOptions controls how a grid of values is laid out as a table.
+type TableOptions struct {
+	// Header is "auto" (the default), which treats the first row as a header
+	// when it looks like one, "first-row" or "none".
+	Header string
+}
+
+// TableLayout describes how a grid of values is rendered as a table.
+type TableLayout struct {
+	// Header reports whether the first row is a header.
+	Header bool
+	// Width is the number of columns.
+	Width int
+	// Numeric marks the columns whose body cells are all numbers, which are
+	// aligned to the right.
+	Numeric []bool
+}
+
+// LayoutTable decides whether the first row of values is a header and which
+// columns are numeric. In auto mode, the first row is a header unless it is
+// empty, contains a number, or repeats a value.
+func LayoutTable(values [][]interface{}, opts TableOptions) (*TableLayout, error) {
+	layout := &TableLayout{}
+	for _, row := range values {
+		layout.Width = max(layout.Width, len(row))
+	}
+
+	switch opts.Header {
+	case "", "auto":
+		layout.Header = len(values) > 0 && looksLikeHeader(values[0])
+	case "first-row":
+		layout.Header = len(values) > 0
+	case "none":
+	default:
+		return nil, fmt.Errorf("invalid table header option: %s. Valid options are: auto, first-row, none", opts.Header)
+	}
+
+	body := values
+	if layout.Header {
+		body = values[1:]
+	}
+	layout.Numeric = make([]bool, layout.Width)
+	for col := range layout.Numeric {
+		numeric, seen := true, false
+		for _, row := range body {
+			if col >= len(row) || strings.TrimSpace(CellString(row[col])) == "" {
+				continue
+			}
+			seen = true
+			if !looksNumeric(row[col]) {
+				numeric = false
+				break
+			}
+		}
+		layout.Numeric[col] = numeric && seen
+	}
+	return layout, nil
+}
+
+func looksLikeHeader(row []interface{}) bool {
+	var seen []string
+	for _, cell := range row {
+		text := strings.TrimSpace(CellString(cell))
+		if text == "" {
+			continue
+		}
+		if looksNumeric(cell) || slices.Contains(seen, text) {
+			return false
+		}
+		seen = append(seen, text)
+	}
+	return len(seen) > 0
+}
+
+// looksNumeric reports whether a cell holds a number, including formatted
+// values such as -1,234.5, (12), $5.00 or 15%.
+func looksNumeric(v interface{}) bool {
+	switch v.(type) {
+	case float64, int, int64:
+		return true
+	}
+	s := strings.TrimSpace(CellString(v))
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = s[1 : len(s)-1]
+	}
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	s = strings.TrimLeft(s, "$€£¥")
+	s = strings.TrimSuffix(s, "%")
+	return numberPattern.MatchString(s)
+}
+
+// markdownEscaper escapes the characters that would otherwise be read as
+// Markdown or HTML, or end a table cell.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "`", "\\`", "*", `\*`, "[", `\[`, "]", `\]`, "<", `\<`,
+	"\r\n", "<br>", "\n", "<br>",
+)
+
+// MarkdownTable renders values as a GitHub-flavoured Markdown table. Without
+// a header row, the columns are headed with their letters.
+func MarkdownTable(values [][]interface{}, opts TableOptions) (string, error) {
+	layout, err := LayoutTable(values, opts)
+	if err != nil || layout.Width == 0 {
+		return "", err
+	}
+
+	var b strings.Builder
+	writeRow := func(row []interface{}) {
+		b.WriteString("|")
+		for i := 0; i < layout.Width; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = markdownEscaper.Replace(strings.TrimSpace(CellString(row[i])))
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	body := values
+	if layout.Header {
+		writeRow(values[0])
+		body = values[1:]
+	} else {
+		letters := make([]interface{}, layout.Width)
+		for i := range letters {
+			letters[i] = a1.ColumnName(i)
+		}
+		writeRow(letters)
+	}
+	b.WriteString("|")
+	for _, numeric := range layout.Numeric {
+		if numeric {
+			b.WriteString(" ---: |")
+		} else {
+			b.WriteString(" --- |")
+		}
+	}
+	b.WriteString("\n")
+	for _, row := range body {
+		writeRow(row)
+	}
+	return b.String(), nil
+}
+
+// HTMLTable renders values as an HTML table, with a thead when the first row is a header.
+func HTMLTable(values [][]interface{}, opts TableOptions) (string, error) {
+	layout, err := LayoutTable(values, opts)
+	if err != nil || layout.Width == 0 {
+		return "", err
+	}
+
+	var b strings.Builder
+	writeRow := func(row []interface{}, tag string) {
+		b.WriteString("    <tr>")
+		for i := 0; i < layout.Width; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = html.EscapeString(strings.TrimSpace(CellString(row[i])))
+				cell = strings.ReplaceAll(strings.ReplaceAll(cell, "\r\n", "\n"), "\n", "<br>")
+			}
+			if layout.Numeric[i] {
+				fmt.Fprintf(&b, `<%s style="text-align: right">%s</%s>`, tag, cell, tag)
+			} else {
+				fmt.Fprintf(&b, "<%s>%s</%s>", tag, cell, tag)
+			}
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n")
+	body := values
+	if layout.Header {
+		b.WriteString("  <thead>\n")
+		writeRow(values[0], "th")
+		b.WriteString("  </thead>\n")
+		body = values[1:]
+	}
+	b.WriteString("  <tbody>\n")
+	for _, row := range body {
+		writeRow(row, "td")
+	}
+	b.WriteString("  </tbody>\n</table>\n")
+	return b.String(), nil
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ghchinoy/drivectl/internal/a1"
+	"google.golang.org/api/sheets/v4"
+)
+
+// UpsertOptions controls how PlanUpsert merges rows into a sheet.
+type UpsertOptions struct {
+	// Key is the header of the column that identifies each row.
+	Key string
+	// DeleteMissing deletes sheet rows whose key is not in the input.
+	DeleteMissing bool
+	// Input is "user-entered" (the default) or "raw", as for ImportOptions.
+	Input string
+	// Read controls how the current values are rendered before they are compared.
+	Read ValueRenderOptions
+}
+
+// UpsertCell is a cell whose value will change.
+type UpsertCell struct {
+	Column string `json:"column"`
+	Cell   string `json:"cell"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// UpsertRow is a row that will be updated, appended or deleted. Row is the
+// 1-based row number on the sheet; it is zero for appended rows.
+type UpsertRow struct {
+	Key   string       `json:"key"`
+	Row   int          `json:"row,omitempty"`
+	Cells []UpsertCell `json:"cells,omitempty"`
+}
+
+// UpsertResult summarises the changes an upsert makes.
+type UpsertResult struct {
+	SpreadsheetID string       `json:"spreadsheetId"`
+	Sheet         string       `json:"sheet"`
+	Key           string       `json:"key"`
+	AddedColumns  []string     `json:"addedColumns"`
+	Updated       []*UpsertRow `json:"updated"`
+	Appended      []*UpsertRow `json:"appended"`
+	Deleted       []*UpsertRow `json:"deleted"`
+	Unchanged     int          `json:"unchanged"`
+	Requests      int          `json:"requests"`
+}
+
+// UpsertPlan is the set of writes that brings a sheet in line with the input.
+type UpsertPlan struct {
+	Result *UpsertResult
+
+	input      string
+	header     []interface{}
+	headerFrom int
+	cells      map[string][][]interface{}
+	appendRows [][]interface{}
+	// appendFrom is the zero-based row below the last row of the sheet.
+	appendFrom int
+	deleteRows []int
+}
+
+// Empty reports whether the plan makes no changes.
+func (p *UpsertPlan) Empty() bool {
+	return p.Result.Requests == 0
+}
+
+// PlanUpsert reads a sheet and works out how to merge a grid of values into
+// it. The first row of the grid and of the sheet are headers; rows are
+// matched by the value in the key column and cells by column header. Only the
+// columns present in the input are compared, and input columns that the sheet
+// lacks are added to the end of its header row.
+func PlanUpsert(sheetsSvc *sheets.Service, spreadsheetId string, sheetName string, values [][]interface{}, opts UpsertOptions) (*UpsertPlan, error) {
+	if _, err := valueInputOption(opts.Input); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("the input has no header row")
+	}
+	inHeader := make([]string, len(values[0]))
+	for i, cell := range values[0] {
+		inHeader[i] = strings.TrimSpace(CellString(cell))
+		if inHeader[i] != "" && slices.Index(inHeader, inHeader[i]) < i {
+			return nil, fmt.Errorf("duplicate column %q in the input", inHeader[i])
+		}
+	}
+	inKey := slices.Index(inHeader, opts.Key)
+	if inKey < 0 {
+		return nil, fmt.Errorf("key column %q not found in the input (columns: %s)", opts.Key, strings.Join(inHeader, ", "))
+	}
+
+	current, err := GetSheetRange(sheetsSvc, spreadsheetId, sheetName, "", opts.Read)
+	if err != nil {
+		return nil, err
+	}
+	var header []string
+	if len(current) > 0 {
+		for _, cell := range current[0] {
+			header = append(header, strings.TrimSpace(CellString(cell)))
+		}
+	}
+	if !slices.Contains(header, opts.Key) && len(current) > 1 {
+		return nil, fmt.Errorf("key column %q not found on sheet %s (columns: %s)", opts.Key, sheetName, strings.Join(header, ", "))
+	}
+
+	p := &UpsertPlan{
+		Result: &UpsertResult{
+			SpreadsheetID: spreadsheetId,
+			Sheet:         sheetName,
+			Key:           opts.Key,
+			AddedColumns:  []string{},
+			Updated:       []*UpsertRow{},
+			Appended:      []*UpsertRow{},
+			Deleted:       []*UpsertRow{},
+		},
+		input:      opts.Input,
+		headerFrom: len(header),
+		appendFrom: max(len(current), 1),
+		cells:      make(map[string][][]interface{}),
+	}
+
+	// Map each input column to a sheet column, adding the ones the sheet lacks.
+	columns := make([]int, len(inHeader))
+	for i, h := range inHeader {
+		columns[i] = -1
+		if h == "" {
+			continue
+		}
+		columns[i] = slices.Index(header, h)
+		if columns[i] < 0 {
+			header = append(header, h)
+			columns[i] = len(header) - 1
+			p.Result.AddedColumns = append(p.Result.AddedColumns, h)
+			p.header = append(p.header, h)
+		}
+	}
+	key := slices.Index(header, opts.Key)
+
+	rows := make(map[string]int)
+	for i := 1; i < len(current); i++ {
+		k := cellText(current[i], key)
+		if k == "" {
+			continue
+		}
+		if prev, dup := rows[k]; dup {
+			return nil, fmt.Errorf("duplicate key %q on sheet %s (rows %d and %d)", k, sheetName, prev+1, i+1)
+		}
+		rows[k] = i
+	}
+
+	seen := make(map[string]int)
+	for r, in := range values[1:] {
+		if blankRow(in) {
+			continue
+		}
+		k := cellText(in, inKey)
+		if k == "" {
+			return nil, fmt.Errorf("input row %d has no value in the key column %q", r+2, opts.Key)
+		}
+		if prev, dup := seen[k]; dup {
+			return nil, fmt.Errorf("duplicate key %q in the input (rows %d and %d)", k, prev, r+2)
+		}
+		seen[k] = r + 2
+
+		i, ok := rows[k]
+		if !ok {
+			row := make([]interface{}, len(header))
+			for c := range row {
+				row[c] = ""
+			}
+			for j, c := range columns {
+				if c >= 0 && j < len(in) {
+					row[c] = in[j]
+				}
+			}
+			p.appendRows = append(p.appendRows, row)
+			p.Result.Appended = append(p.Result.Appended, &UpsertRow{Key: k})
+			continue
+		}
+
+		change := &UpsertRow{Key: k, Row: i + 1}
+		changed := make(map[int]interface{})
+		for j, c := range columns {
+			if c < 0 {
+				continue
+			}
+			from, to := cellText(current[i], c), cellText(in, j)
+			if from == to {
+				continue
+			}
+			var v interface{} = ""
+			if j < len(in) {
+				v = in[j]
+			}
+			changed[c] = v
+			change.Cells = append(change.Cells, UpsertCell{
+				Column: header[c],
+				Cell:   a1.Cell{Col: c, Row: i}.String(),
+				From:   from,
+				To:     to,
+			})
+		}
+		if len(changed) == 0 {
+			p.Result.Unchanged++
+			continue
+		}
+		p.addCellRuns(sheetName, i, changed)
+		p.Result.Updated = append(p.Result.Updated, change)
+	}
+
+	if opts.DeleteMissing {
+		for i := 1; i < len(current); i++ {
+			k := cellText(current[i], key)
+			if _, ok := seen[k]; k == "" || ok {
+				continue
+			}
+			p.deleteRows = append(p.deleteRows, i)
+			p.Result.Deleted = append(p.Result.Deleted, &UpsertRow{Key: k, Row: i + 1})
+		}
+	}
+
+	if len(p.header) > 0 {
+		added := [][]interface{}{p.header}
+		p.cells[a1.Covering(sheetName, a1.Cell{Col: p.headerFrom}, added).String()] = added
+	}
+	for _, n := range []int{len(p.cells), len(p.appendRows), len(p.deleteRows)} {
+		if n > 0 {
+			p.Result.Requests++
+		}
+	}
+	return p, nil
+}
+
+// addCellRuns adds the changed cells of one zero-based row to the plan,
+// merging adjacent columns into a single range.
+func (p *UpsertPlan) addCellRuns(sheetName string, row int, changed map[int]interface{}) {
+	cols := make([]int, 0, len(changed))
+	for c := range changed {
+		cols = append(cols, c)
+	}
+	slices.Sort(cols)
+	for start := 0; start < len(cols); {
+		end := start + 1
+		for end < len(cols) && cols[end] == cols[end-1]+1 {
+			end++
+		}
+		run := make([]interface{}, 0, end-start)
+		for _, c := range cols[start:end] {
+			run = append(run, changed[c])
+		}
+		values := [][]interface{}{run}
+		p.cells[a1.Covering(sheetName, a1.Cell{Col: cols[start], Row: row}, values).String()] = values
+		start = end
+	}
+}
+
+// Apply makes the planned changes with at most three requests: one values
+// update for the new header columns and changed cells, one batch of row
+// deletions and one append for the new rows. Rows are deleted before any are
+// added, so the row numbers found by PlanUpsert still hold, and new rows are
+// written below the last row of the sheet rather than after the first block
+// of data the API finds, which may end at a blank row.
+func (p *UpsertPlan) Apply(sheetsSvc *sheets.Service) error {
+	id, sheetName := p.Result.SpreadsheetID, p.Result.Sheet
+	if len(p.cells) > 0 {
+		if _, err := BatchUpdateValues(sheetsSvc, id, p.cells, p.input); err != nil {
+			return err
+		}
+	}
+	if len(p.deleteRows) > 0 {
+		info, err := FindSheet(sheetsSvc, id, sheetName)
+		if err != nil {
+			return err
+		}
+		// Delete from the bottom up so earlier deletions do not shift later ones,
+		// merging adjacent rows into a single range.
+		var reqs []*sheets.Request
+		for end := len(p.deleteRows); end > 0; {
+			start := end - 1
+			for start > 0 && p.deleteRows[start-1] == p.deleteRows[start]-1 {
+				start--
+			}
+			reqs = append(reqs, &sheets.Request{
+				DeleteDimension: &sheets.DeleteDimensionRequest{
+					Range: &sheets.DimensionRange{
+						SheetId:    info.SheetID,
+						Dimension:  "ROWS",
+						StartIndex: int64(p.deleteRows[start]),
+						EndIndex:   int64(p.deleteRows[end-1] + 1),
+					},
+				},
+			})
+			end = start
+		}
+		if _, err := batchUpdate(sheetsSvc, id, reqs...); err != nil {
+			return fmt.Errorf("unable to delete rows: %w", err)
+		}
+	}
+	if len(p.appendRows) > 0 {
+		input, err := valueInputOption(p.input)
+		if err != nil {
+			return err
+		}
+		// Nothing is below this row, so the append writes at the row itself.
+		from := a1.Cell{Row: p.appendFrom - len(p.deleteRows)}.In(sheetName).String()
+		_, err = sheetsSvc.Spreadsheets.Values.Append(id, from, &sheets.ValueRange{
+			Values: p.appendRows,
+		}).ValueInputOption(input).InsertDataOption("INSERT_ROWS").Do()
+		if err != nil {
+			return fmt.Errorf("unable to append rows: %w", err)
+		}
+	}
+	return nil
+}
+
+// cellText returns the text of a cell, or "" when the row is too short.
+func cellText(row []interface{}, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return CellString(row[i])
+}
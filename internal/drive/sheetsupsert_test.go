@@ -0,0 +1,230 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ghchinoy/drivectl/internal/a1"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// fakeSheet serves one sheet of text cells over the parts of the Sheets API
+// that upserts use. Appends find the table to add to the way the API does:
+// the first block of non-blank rows at or below the requested row.
+type fakeSheet struct {
+	t     *testing.T
+	grid  [][]string
+	calls []string
+}
+
+const fakeSheetID = 7
+
+func (f *fakeSheet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/id")
+	var resp interface{} = struct{}{}
+	switch {
+	case r.Method == http.MethodGet && path == "":
+		f.calls = append(f.calls, "get")
+		resp = &sheets.Spreadsheet{Sheets: []*sheets.Sheet{{
+			Properties: &sheets.SheetProperties{SheetId: fakeSheetID, Title: "Sheet1"},
+		}}}
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/values/"):
+		f.calls = append(f.calls, "read")
+		resp = &sheets.ValueRange{Values: f.values()}
+	case path == "/values:batchUpdate":
+		var req sheets.BatchUpdateValuesRequest
+		f.decode(r, &req)
+		for _, data := range req.Data {
+			f.calls = append(f.calls, "update "+data.Range)
+			f.write(f.parse(data.Range).Start(), data.Values)
+		}
+	case strings.HasSuffix(path, ":append"):
+		rng := strings.TrimSuffix(strings.TrimPrefix(path, "/values/"), ":append")
+		f.calls = append(f.calls, "append "+rng)
+		var req sheets.ValueRange
+		f.decode(r, &req)
+		at := f.parse(rng).StartRow
+		for at < len(f.grid) && blank(f.grid[at]) {
+			at++
+		}
+		if at == len(f.grid) {
+			at = f.parse(rng).StartRow
+		} else {
+			for at < len(f.grid) && !blank(f.grid[at]) {
+				at++
+			}
+		}
+		for at > len(f.grid) {
+			f.grid = append(f.grid, nil)
+		}
+		f.grid = slices.Insert(f.grid, at, make([][]string, len(req.Values))...)
+		f.write(a1.Cell{Row: at}, req.Values)
+	case path == ":batchUpdate":
+		var req sheets.BatchUpdateSpreadsheetRequest
+		f.decode(r, &req)
+		for _, rq := range req.Requests {
+			d := rq.DeleteDimension.Range
+			if d.SheetId != fakeSheetID || d.Dimension != "ROWS" {
+				f.t.Fatalf("unexpected delete %+v", d)
+			}
+			f.calls = append(f.calls, "delete "+a1.Range{StartRow: int(d.StartIndex), EndRow: int(d.EndIndex)}.String())
+			f.grid = slices.Delete(f.grid, int(d.StartIndex), int(d.EndIndex))
+		}
+	default:
+		f.t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeSheet) decode(r *http.Request, v interface{}) {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		f.t.Fatalf("unable to decode %s: %v", r.URL.Path, err)
+	}
+}
+
+func (f *fakeSheet) parse(ref string) a1.Range {
+	rng, err := a1.ParseRange(ref)
+	if err != nil {
+		f.t.Fatalf("unable to parse range %q: %v", ref, err)
+	}
+	return rng
+}
+
+// values returns the grid as the API does, without trailing blank rows.
+func (f *fakeSheet) values() [][]interface{} {
+	var values [][]interface{}
+	for _, row := range f.grid {
+		cells := make([]interface{}, len(row))
+		for i, cell := range row {
+			cells[i] = cell
+		}
+		values = append(values, cells)
+	}
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	return values
+}
+
+func (f *fakeSheet) write(at a1.Cell, values [][]interface{}) {
+	for r, row := range values {
+		for len(f.grid) <= at.Row+r {
+			f.grid = append(f.grid, nil)
+		}
+		for c, v := range row {
+			for len(f.grid[at.Row+r]) <= at.Col+c {
+				f.grid[at.Row+r] = append(f.grid[at.Row+r], "")
+			}
+			f.grid[at.Row+r][at.Col+c] = CellString(v)
+		}
+	}
+}
+
+func blank(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		name          string
+		grid          [][]string
+		input         [][]interface{}
+		deleteMissing bool
+		want          [][]string
+		wantCalls     []string
+	}{
+		{
+			name: "update append and delete around a blank row",
+			grid: [][]string{
+				{"id", "name"},
+				{"1", "a"},
+				{"2", "b"},
+				nil,
+				{"3", "c"},
+				{"4", "d"},
+			},
+			input:         [][]interface{}{{"id", "name"}, {"1", "a"}, {"3", "C"}, {"5", "e"}},
+			deleteMissing: true,
+			want: [][]string{
+				{"id", "name"},
+				{"1", "a"},
+				nil,
+				{"3", "C"},
+				{"5", "e"},
+			},
+			wantCalls: []string{"read", "update Sheet1!B5", "get", "delete 6:6", "delete 3:3", "append Sheet1!A5"},
+		},
+		{
+			name:      "append below a blank row",
+			grid:      [][]string{{"id", "name"}, {"1", "a"}, nil, {"2", "b"}},
+			input:     [][]interface{}{{"id", "name"}, {"3", "c"}},
+			want:      [][]string{{"id", "name"}, {"1", "a"}, nil, {"2", "b"}, {"3", "c"}},
+			wantCalls: []string{"read", "append Sheet1!A5"},
+		},
+		{
+			name:      "empty sheet",
+			input:     [][]interface{}{{"id", "name"}, {"1", "a"}, {"2", "b"}},
+			want:      [][]string{{"id", "name"}, {"1", "a"}, {"2", "b"}},
+			wantCalls: []string{"read", "update Sheet1!A1:B1", "append Sheet1!A2"},
+		},
+		{
+			name:          "new column and deletions only",
+			grid:          [][]string{{"id"}, {"1"}, {"2"}, {"3"}},
+			input:         [][]interface{}{{"id", "note"}, {"2", "x"}},
+			deleteMissing: true,
+			want:          [][]string{{"id", "note"}, {"2", "x"}},
+			wantCalls:     []string{"read", "update Sheet1!B1", "update Sheet1!B3", "get", "delete 4:4", "delete 2:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSheet{t: t, grid: tt.grid}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+			svc, err := sheets.NewService(context.Background(), option.WithEndpoint(srv.URL+"/"), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			plan, err := PlanUpsert(svc, "id", "Sheet1", tt.input, UpsertOptions{Key: "id", DeleteMissing: tt.deleteMissing})
+			if err != nil {
+				t.Fatalf("PlanUpsert returned error: %v", err)
+			}
+			if err := plan.Apply(svc); err != nil {
+				t.Fatalf("Apply returned error: %v", err)
+			}
+			if !reflect.DeepEqual(fake.grid, tt.want) {
+				t.Errorf("sheet after upsert = %q, want %q", fake.grid, tt.want)
+			}
+			if !reflect.DeepEqual(fake.calls, tt.wantCalls) {
+				t.Errorf("requests = %q, want %q", fake.calls, tt.wantCalls)
+			}
+		})
+	}
+}
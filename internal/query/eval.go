@@ -0,0 +1,652 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Result is the output of a query.
+type Result struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Run evaluates the query over rows whose columns are named by header.
+// Header names are matched case-insensitively.
+func (q *Query) Run(header []string, rows [][]interface{}) (*Result, error) {
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if _, dup := cols[key]; !dup {
+			cols[key] = i
+		}
+	}
+
+	items := q.Items
+	if q.Star {
+		items = make([]SelectItem, len(header))
+		for i, h := range header {
+			items[i] = SelectItem{Expr: &ColumnRef{h}}
+		}
+	}
+	aliases := make(map[string]Expr)
+	for _, item := range items {
+		if item.Alias != "" {
+			aliases[strings.ToLower(item.Alias)] = item.Expr
+		}
+	}
+
+	// Resolve GROUP BY terms that name a select alias, then check every column reference.
+	groupBy := make([]Expr, len(q.GroupBy))
+	for i, e := range q.GroupBy {
+		groupBy[i] = e
+		if c, ok := e.(*ColumnRef); ok {
+			if _, isCol := cols[strings.ToLower(c.Name)]; !isCol {
+				if aliased, ok := aliases[strings.ToLower(c.Name)]; ok {
+					groupBy[i] = aliased
+				}
+			}
+		}
+	}
+	var exprs []Expr
+	for _, item := range items {
+		exprs = append(exprs, item.Expr)
+	}
+	exprs = append(exprs, groupBy...)
+	if q.Where != nil {
+		exprs = append(exprs, q.Where)
+	}
+	for _, e := range exprs {
+		if err := checkColumns(e, cols, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range q.OrderBy {
+		if err := checkColumns(o.Expr, cols, aliases); err != nil {
+			return nil, err
+		}
+	}
+	if q.Where != nil && hasAggregate(q.Where) {
+		return nil, fmt.Errorf("aggregates are not allowed in WHERE")
+	}
+
+	var filtered [][]interface{}
+	for _, row := range rows {
+		row = normaliseRow(row)
+		if q.Where != nil {
+			v, err := (&evalCtx{cols: cols, row: row}).eval(q.Where)
+			if err != nil {
+				return nil, err
+			}
+			if !truthy(v) {
+				continue
+			}
+		}
+		filtered = append(filtered, row)
+	}
+
+	aggregate := len(groupBy) > 0
+	for _, e := range exprs {
+		aggregate = aggregate || hasAggregate(e)
+	}
+	for _, o := range q.OrderBy {
+		aggregate = aggregate || hasAggregate(o.Expr)
+	}
+	if aggregate && q.Star {
+		return nil, fmt.Errorf("SELECT * cannot be combined with GROUP BY or aggregates")
+	}
+
+	// Each output row keeps the context it was computed in, so ORDER BY can
+	// evaluate expressions that are not in the select list.
+	var contexts []*evalCtx
+	if aggregate {
+		groups, err := groupRows(filtered, groupBy, cols)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			ctx := &evalCtx{cols: cols, aliases: aliases, aggregate: true, group: g}
+			if len(g) > 0 {
+				ctx.row = g[0]
+			}
+			contexts = append(contexts, ctx)
+		}
+	} else {
+		for _, row := range filtered {
+			contexts = append(contexts, &evalCtx{cols: cols, aliases: aliases, row: row})
+		}
+	}
+
+	result := &Result{Columns: make([]string, len(items)), Rows: make([][]interface{}, len(contexts))}
+	for i, item := range items {
+		result.Columns[i] = item.Name()
+	}
+	for i, ctx := range contexts {
+		out := make([]interface{}, len(items))
+		for j, item := range items {
+			v, err := ctx.eval(item.Expr)
+			if err != nil {
+				return nil, err
+			}
+			out[j] = v
+		}
+		result.Rows[i] = out
+	}
+
+	if len(q.OrderBy) > 0 {
+		if err := q.sort(result, contexts); err != nil {
+			return nil, err
+		}
+	}
+
+	start := min(q.Offset, len(result.Rows))
+	result.Rows = result.Rows[start:]
+	if q.Limit >= 0 && q.Limit < len(result.Rows) {
+		result.Rows = result.Rows[:q.Limit]
+	}
+	return result, nil
+}
+
+// sort orders the result rows. ORDER BY terms that name an output column use
+// its value; other terms are evaluated against each row's context.
+func (q *Query) sort(result *Result, contexts []*evalCtx) error {
+	keys := make([][]interface{}, len(result.Rows))
+	for i := range result.Rows {
+		keys[i] = make([]interface{}, len(q.OrderBy))
+		for j, o := range q.OrderBy {
+			if c, ok := o.Expr.(*ColumnRef); ok {
+				if idx := indexFold(result.Columns, c.Name); idx >= 0 {
+					keys[i][j] = result.Rows[i][idx]
+					continue
+				}
+			}
+			v, err := contexts[i].eval(o.Expr)
+			if err != nil {
+				return err
+			}
+			keys[i][j] = v
+		}
+	}
+
+	order := make([]int, len(result.Rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		for j, o := range q.OrderBy {
+			c := sortCompare(keys[order[a]][j], keys[order[b]][j])
+			if c == 0 {
+				continue
+			}
+			if o.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	rows := make([][]interface{}, len(order))
+	for i, idx := range order {
+		rows[i] = result.Rows[idx]
+	}
+	result.Rows = rows
+	return nil
+}
+
+func indexFold(list []string, s string) int {
+	for i, v := range list {
+		if strings.EqualFold(v, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// groupRows splits rows into groups with equal GROUP BY values, in the order
+// each group first appears. With no GROUP BY terms, all rows form one group.
+func groupRows(rows [][]interface{}, groupBy []Expr, cols map[string]int) ([][][]interface{}, error) {
+	if len(groupBy) == 0 {
+		return [][][]interface{}{rows}, nil
+	}
+	var groups [][][]interface{}
+	index := make(map[string]int)
+	for _, row := range rows {
+		ctx := &evalCtx{cols: cols, row: row}
+		var key strings.Builder
+		for _, e := range groupBy {
+			v, err := ctx.eval(e)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&key, "%T:%s\x00", v, formatValue(v))
+		}
+		i, ok := index[key.String()]
+		if !ok {
+			i = len(groups)
+			index[key.String()] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], row)
+	}
+	return groups, nil
+}
+
+func checkColumns(e Expr, cols map[string]int, aliases map[string]Expr) error {
+	var err error
+	walk(e, func(e Expr) {
+		c, ok := e.(*ColumnRef)
+		if !ok || err != nil {
+			return
+		}
+		key := strings.ToLower(c.Name)
+		if _, ok := cols[key]; ok {
+			return
+		}
+		if _, ok := aliases[key]; ok {
+			return
+		}
+		err = fmt.Errorf("unknown column %q", c.Name)
+	})
+	return err
+}
+
+func hasAggregate(e Expr) bool {
+	found := false
+	walk(e, func(e Expr) {
+		if c, ok := e.(*Call); ok && aggregates[c.Name] {
+			found = true
+		}
+	})
+	return found
+}
+
+func walk(e Expr, fn func(Expr)) {
+	fn(e)
+	switch e := e.(type) {
+	case *Unary:
+		walk(e.X, fn)
+	case *Binary:
+		walk(e.L, fn)
+		walk(e.R, fn)
+	case *In:
+		walk(e.X, fn)
+		for _, x := range e.List {
+			walk(x, fn)
+		}
+	case *IsNull:
+		walk(e.X, fn)
+	case *Call:
+		for _, x := range e.Args {
+			walk(x, fn)
+		}
+	}
+}
+
+// normaliseRow converts empty cells to nil so they behave as NULL.
+func normaliseRow(row []interface{}) []interface{} {
+	out := make([]interface{}, len(row))
+	for i, v := range row {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) == "" {
+			v = nil
+		}
+		out[i] = v
+	}
+	return out
+}
+
+type evalCtx struct {
+	cols map[string]int
+	// aliases maps select aliases to their expressions, for ORDER BY.
+	aliases map[string]Expr
+	row     []interface{}
+	// aggregate is set when the context stands for a group of rows, which
+	// may be empty when no rows match a query without GROUP BY.
+	aggregate bool
+	group     [][]interface{}
+}
+
+func (c *evalCtx) eval(e Expr) (interface{}, error) {
+	switch e := e.(type) {
+	case *ColumnRef:
+		idx, ok := c.cols[strings.ToLower(e.Name)]
+		if !ok {
+			if aliased, ok := c.aliases[strings.ToLower(e.Name)]; ok {
+				return c.eval(aliased)
+			}
+			return nil, fmt.Errorf("unknown column %q", e.Name)
+		}
+		if idx < len(c.row) {
+			return c.row[idx], nil
+		}
+		return nil, nil
+	case *Literal:
+		return e.Value, nil
+	case *Unary:
+		x, err := c.eval(e.X)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		if e.Op == "NOT" {
+			return !truthy(x), nil
+		}
+		if n, ok := toNumber(x); ok {
+			return -n, nil
+		}
+		return nil, nil
+	case *Binary:
+		return c.evalBinary(e)
+	case *In:
+		x, err := c.eval(e.X)
+		if err != nil || x == nil {
+			return false, err
+		}
+		for _, item := range e.List {
+			v, err := c.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			if cmp, ok := compare(x, v); ok && cmp == 0 {
+				return !e.Not, nil
+			}
+		}
+		return e.Not, nil
+	case *IsNull:
+		x, err := c.eval(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return (x == nil) != e.Not, nil
+	case *Call:
+		if aggregates[e.Name] {
+			return c.evalAggregate(e)
+		}
+		x, err := c.eval(e.Args[0])
+		if err != nil || x == nil {
+			return nil, err
+		}
+		switch e.Name {
+		case "LOWER":
+			return strings.ToLower(formatValue(x)), nil
+		case "UPPER":
+			return strings.ToUpper(formatValue(x)), nil
+		case "LENGTH":
+			return float64(utf8.RuneCountInString(formatValue(x))), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot evaluate %s", e)
+}
+
+func (c *evalCtx) evalBinary(e *Binary) (interface{}, error) {
+	l, err := c.eval(e.L)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case "AND":
+		if !truthy(l) {
+			return false, nil
+		}
+		r, err := c.eval(e.R)
+		return truthy(r), err
+	case "OR":
+		if truthy(l) {
+			return true, nil
+		}
+		r, err := c.eval(e.R)
+		return truthy(r), err
+	}
+
+	r, err := c.eval(e.R)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Op {
+	case "=", "!=", "<", "<=", ">", ">=":
+		cmp, ok := compare(l, r)
+		if !ok {
+			return false, nil
+		}
+		switch e.Op {
+		case "=":
+			return cmp == 0, nil
+		case "!=":
+			return cmp != 0, nil
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "LIKE":
+		if l == nil || r == nil {
+			return false, nil
+		}
+		re, err := likePattern(formatValue(r))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(formatValue(l)), nil
+	}
+
+	a, aok := toNumber(l)
+	b, bok := toNumber(r)
+	if !aok || !bok {
+		return nil, nil
+	}
+	switch e.Op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, nil
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, nil
+		}
+		return float64(int64(a) % int64(b)), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", e.Op)
+}
+
+func (c *evalCtx) evalAggregate(e *Call) (interface{}, error) {
+	if !c.aggregate {
+		return nil, fmt.Errorf("%s is only allowed in SELECT and ORDER BY", e)
+	}
+	if e.Star {
+		return float64(len(c.group)), nil
+	}
+
+	var values []interface{}
+	for _, row := range c.group {
+		v, err := (&evalCtx{cols: c.cols, row: row}).eval(e.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+
+	switch e.Name {
+	case "COUNT":
+		return float64(len(values)), nil
+	case "SUM", "AVG":
+		sum, n := 0.0, 0
+		for _, v := range values {
+			if f, ok := toNumber(v); ok {
+				sum += f
+				n++
+			}
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		if e.Name == "AVG" {
+			return sum / float64(n), nil
+		}
+		return sum, nil
+	case "MIN", "MAX":
+		var best interface{}
+		for _, v := range values {
+			if best == nil {
+				best = v
+				continue
+			}
+			cmp := sortCompare(v, best)
+			if (e.Name == "MIN" && cmp < 0) || (e.Name == "MAX" && cmp > 0) {
+				best = v
+			}
+		}
+		return best, nil
+	}
+	return nil, fmt.Errorf("unknown aggregate %s", e.Name)
+}
+
+func truthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+	return false
+}
+
+// numberPattern matches numbers as a spreadsheet may format them, such as
+// -1,234.5, $12 or 15%.
+var numberPattern = regexp.MustCompile(`^([-+]?)[$€£¥]?(\d+|\d{1,3}(?:,\d{3})+)?(\.\d+)?(%?)$`)
+
+// toNumber converts a value to a number if it is one or is text that looks like one.
+func toNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		m := numberPattern.FindStringSubmatch(strings.TrimSpace(v))
+		if m == nil || m[2]+m[3] == "" {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(m[1]+strings.ReplaceAll(m[2], ",", "")+m[3], 64)
+		if err != nil {
+			return 0, false
+		}
+		if m[4] == "%" {
+			n /= 100
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// compare orders two non-null values, numerically when both are numbers.
+// It reports false when either value is NULL.
+func compare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case !x:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return strings.Compare(formatValue(a), formatValue(b)), true
+}
+
+// sortCompare is compare with NULL ordered before every other value.
+func sortCompare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	c, _ := compare(a, b)
+	return c
+}
+
+// likePattern converts a LIKE pattern, where % matches any run of characters
+// and _ matches one, into a case-insensitive regular expression.
+func likePattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	testHeader = []string{"Name", "Region", "Sales", "Notes"}
+	testRows   = [][]interface{}{
+		{"Ann", "EU", "100", "vip"},
+		{"Bob", "US", "$1,250.50", ""},
+		{"Cid", "EU", "20"},
+		{"Dee", "APAC", "", "new"},
+		{"Eve", "US", "75", "vip"},
+	}
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		src  string
+		rows [][]interface{}
+		want [][]interface{}
+	}{
+		// Rows and columns.
+		{src: "select name where region = 'EU'", rows: testRows, want: [][]interface{}{{"Ann"}, {"Cid"}}},
+		{src: "select name, sales * 2 where sales > 90", rows: testRows, want: [][]interface{}{{"Ann", 200.0}, {"Bob", 2501.0}}},
+		{src: "select name where notes is null", rows: testRows, want: [][]interface{}{{"Bob"}, {"Cid"}}},
+		{src: "select name where notes is not null and notes != 'vip'", rows: testRows, want: [][]interface{}{{"Dee"}}},
+		{src: "select name where sales < 50", rows: testRows, want: [][]interface{}{{"Cid"}}},
+		{src: "select name where region in ('APAC', 'us')", rows: testRows, want: [][]interface{}{{"Dee"}}},
+		{src: "select name where region not in ('EU') and notes is null", rows: testRows, want: [][]interface{}{{"Bob"}}},
+
+		// LIKE.
+		{src: "select name where name like 'a%'", rows: testRows, want: [][]interface{}{{"Ann"}}},
+		{src: "select name where name like '_e_'", rows: testRows, want: [][]interface{}{{"Dee"}}},
+		{src: "select name where name not like '%e%'", rows: testRows, want: [][]interface{}{{"Ann"}, {"Bob"}, {"Cid"}}},
+		{src: "select name where notes like '%'", rows: testRows, want: [][]interface{}{{"Ann"}, {"Dee"}, {"Eve"}}},
+		{src: "select name where name like 'a.n'", rows: testRows, want: nil},
+
+		// Aggregates, including over NULLs and no rows.
+		{src: "select count(*), count(notes), sum(sales), min(sales), max(name)", rows: testRows, want: [][]interface{}{{5.0, 3.0, 1445.5, "20", "Eve"}}},
+		{src: "select avg(sales) where region = 'EU'", rows: testRows, want: [][]interface{}{{60.0}}},
+		{src: "select count(*), sum(sales) where region = 'XX'", rows: testRows, want: [][]interface{}{{0.0, nil}}},
+		{src: "select count(*), count(notes), max(name)", rows: nil, want: [][]interface{}{{0.0, 0.0, nil}}},
+		{src: "select sum(sales) where region = 'APAC'", rows: testRows, want: [][]interface{}{{nil}}},
+		{src: "select region, count(*) group by region", rows: nil, want: nil},
+
+		// GROUP BY and ORDER BY, by column and by alias.
+		{src: "select region, count(*) group by region", rows: testRows, want: [][]interface{}{{"EU", 2.0}, {"US", 2.0}, {"APAC", 1.0}}},
+		{src: "select lower(region) r, count(*) n group by r order by r", rows: testRows, want: [][]interface{}{{"apac", 1.0}, {"eu", 2.0}, {"us", 2.0}}},
+		{src: "select region, sum(sales) total group by region order by total desc", rows: testRows, want: [][]interface{}{{"US", 1325.5}, {"EU", 120.0}, {"APAC", nil}}},
+		{src: "select region group by region order by count(*) desc, region", rows: testRows, want: [][]interface{}{{"EU"}, {"US"}, {"APAC"}}},
+		{src: "select name order by sales", rows: testRows, want: [][]interface{}{{"Dee"}, {"Cid"}, {"Eve"}, {"Ann"}, {"Bob"}}},
+		{src: "select name n order by n desc", rows: testRows, want: [][]interface{}{{"Eve"}, {"Dee"}, {"Cid"}, {"Bob"}, {"Ann"}}},
+		{src: "select name order by region, name desc", rows: testRows, want: [][]interface{}{{"Dee"}, {"Cid"}, {"Ann"}, {"Eve"}, {"Bob"}}},
+
+		// LIMIT and OFFSET.
+		{src: "select name limit 2", rows: testRows, want: [][]interface{}{{"Ann"}, {"Bob"}}},
+		{src: "select name limit 2 offset 3", rows: testRows, want: [][]interface{}{{"Dee"}, {"Eve"}}},
+		{src: "select name order by name desc limit 1 offset 1", rows: testRows, want: [][]interface{}{{"Dee"}}},
+		{src: "select name limit 2 offset 10", rows: testRows, want: [][]interface{}{}},
+		{src: "select name limit 0", rows: testRows, want: [][]interface{}{}},
+		{src: "select count(*) limit 1 offset 1", rows: testRows, want: [][]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			q, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.src, err)
+			}
+			got, err := q.Run(testHeader, tt.rows)
+			if err != nil {
+				t.Fatalf("Run(%q) returned error: %v", tt.src, err)
+			}
+			if len(got.Rows) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got.Rows, tt.want) {
+				t.Errorf("Run(%q) = %v, want %v", tt.src, got.Rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunColumns(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{"select *", testHeader},
+		{"select name, sales as total, count(*) n group by name, sales", []string{"name", "total", "n"}},
+		{"select sum(sales), lower(region) group by region", []string{"sum(sales)", "lower(region)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			q, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.src, err)
+			}
+			got, err := q.Run(testHeader, testRows)
+			if err != nil {
+				t.Fatalf("Run(%q) returned error: %v", tt.src, err)
+			}
+			if !reflect.DeepEqual(got.Columns, tt.want) {
+				t.Errorf("Run(%q) columns = %q, want %q", tt.src, got.Columns, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"select nosuch", `unknown column "nosuch"`},
+		{"select name where total > 1", `unknown column "total"`},
+		{"select name order by nosuch", `unknown column "nosuch"`},
+		{"select name where count(*) > 1", "aggregates are not allowed in WHERE"},
+		{"select * group by region", "SELECT * cannot be combined"},
+		{"select count(*) group by nosuch", `unknown column "nosuch"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			q, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.src, err)
+			}
+			_, err = q.Run(testHeader, testRows)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Run(%q) error = %v, want it to contain %q", tt.src, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package query evaluates a small subset of SQL over rows of spreadsheet data.
+//
+// The supported grammar is:
+//
+//	SELECT * | expr [AS alias], ...
+//	[WHERE expr]
+//	[GROUP BY expr, ...]
+//	[ORDER BY expr [ASC|DESC], ...]
+//	[LIMIT n [OFFSET m]]
+//
+// Expressions support column names (quote names containing spaces with double
+// quotes or backticks), numbers, 'strings', TRUE, FALSE, NULL, arithmetic,
+// comparisons, AND, OR, NOT, LIKE, IN (...), IS [NOT] NULL, the aggregates
+// COUNT, SUM, AVG, MIN and MAX, and the functions LOWER, UPPER and LENGTH.
+// Empty cells are treated as NULL.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokQuotedIdent
+	tokNumber
+	tokString
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// keyword reports whether the token is the given keyword, ignoring case.
+func (t token) keyword(kw string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+func (t token) symbol(s string) bool {
+	return t.kind == tokSymbol && t.text == s
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q at position %d", t.text, t.pos+1)
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[start:i]), start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[start:i]), start})
+		case r == '\'' || r == '"' || r == '`':
+			start := i
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated quote starting at position %d", start+1)
+				}
+				if runes[i] == r {
+					// A doubled quote stands for the quote character itself.
+					if i+1 < len(runes) && runes[i+1] == r {
+						b.WriteRune(r)
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			kind := tokQuotedIdent
+			if r == '\'' {
+				kind = tokString
+			}
+			tokens = append(tokens, token{kind, b.String(), start})
+		default:
+			start := i
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "<=", ">=", "<>", "!=":
+					tokens = append(tokens, token{tokSymbol, two, start})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("=<>+-*/%(),", r) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, token{tokSymbol, string(r), start})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,559 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed SELECT statement.
+type Query struct {
+	Star    bool
+	Items   []SelectItem
+	Where   Expr
+	GroupBy []Expr
+	OrderBy []OrderItem
+	Limit   int
+	Offset  int
+}
+
+// SelectItem is one output column of a query.
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+}
+
+// Name returns the output column name: the alias, or the expression text.
+func (s SelectItem) Name() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	if c, ok := s.Expr.(*ColumnRef); ok {
+		return c.Name
+	}
+	return s.Expr.String()
+}
+
+// OrderItem is one ORDER BY term.
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// Expr is a node in an expression tree.
+type Expr interface {
+	String() string
+}
+
+// ColumnRef refers to a column by its header.
+type ColumnRef struct{ Name string }
+
+// Literal is a constant value.
+type Literal struct{ Value interface{} }
+
+// Unary is a prefix operator: "-" or "NOT".
+type Unary struct {
+	Op string
+	X  Expr
+}
+
+// Binary is an infix operator such as "+", "=", "AND" or "LIKE".
+type Binary struct {
+	Op   string
+	L, R Expr
+}
+
+// In tests membership in a list of values.
+type In struct {
+	X    Expr
+	List []Expr
+	Not  bool
+}
+
+// IsNull tests whether a value is NULL.
+type IsNull struct {
+	X   Expr
+	Not bool
+}
+
+// Call is a function or aggregate call. Star is set for COUNT(*).
+type Call struct {
+	Name string
+	Args []Expr
+	Star bool
+}
+
+var aggregates = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true}
+
+var functions = map[string]int{"LOWER": 1, "UPPER": 1, "LENGTH": 1}
+
+func (c *ColumnRef) String() string {
+	if strings.ContainsAny(c.Name, " \t\"") {
+		return `"` + strings.ReplaceAll(c.Name, `"`, `""`) + `"`
+	}
+	return c.Name
+}
+
+func (l *Literal) String() string {
+	switch v := l.Value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	}
+	return formatValue(l.Value)
+}
+
+func (u *Unary) String() string {
+	if u.Op == "NOT" {
+		return "NOT " + u.X.String()
+	}
+	return u.Op + u.X.String()
+}
+
+func (b *Binary) String() string { return b.L.String() + " " + b.Op + " " + b.R.String() }
+
+func (in *In) String() string {
+	parts := make([]string, len(in.List))
+	for i, e := range in.List {
+		parts[i] = e.String()
+	}
+	op := " IN ("
+	if in.Not {
+		op = " NOT IN ("
+	}
+	return in.X.String() + op + strings.Join(parts, ", ") + ")"
+}
+
+func (n *IsNull) String() string {
+	if n.Not {
+		return n.X.String() + " IS NOT NULL"
+	}
+	return n.X.String() + " IS NULL"
+}
+
+func (c *Call) String() string {
+	if c.Star {
+		return strings.ToLower(c.Name) + "(*)"
+	}
+	parts := make([]string, len(c.Args))
+	for i, e := range c.Args {
+		parts[i] = e.String()
+	}
+	return strings.ToLower(c.Name) + "(" + strings.Join(parts, ", ") + ")"
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a query.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q, err := p.query()
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	return q, nil
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptKeyword(kws ...string) bool {
+	for i, kw := range kws {
+		if !p.tokens[min(p.pos+i, len(p.tokens)-1)].keyword(kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *parser) acceptSymbol(s string) bool {
+	if p.peek().symbol(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kws ...string) error {
+	if !p.acceptKeyword(kws...) {
+		return fmt.Errorf("expected %s, found %s", strings.Join(kws, " "), p.peek())
+	}
+	return nil
+}
+
+func (p *parser) expectSymbol(s string) error {
+	if !p.acceptSymbol(s) {
+		return fmt.Errorf("expected %q, found %s", s, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) query() (*Query, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	q := &Query{Limit: -1}
+	if p.acceptSymbol("*") {
+		q.Star = true
+	} else {
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := SelectItem{Expr: e}
+			if p.acceptKeyword("AS") {
+				t := p.next()
+				if t.kind != tokIdent && t.kind != tokQuotedIdent && t.kind != tokString {
+					return nil, fmt.Errorf("expected an alias after AS, found %s", t)
+				}
+				item.Alias = t.text
+			} else if t := p.peek(); t.kind == tokQuotedIdent || (t.kind == tokIdent && !clauseKeyword(t)) {
+				p.next()
+				item.Alias = t.text
+			}
+			q.Items = append(q.Items, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.peek().keyword("FROM") {
+		return nil, fmt.Errorf("FROM is not supported; the rows to query are chosen outside the query")
+	}
+	if p.acceptKeyword("WHERE") {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = e
+	}
+	if p.acceptKeyword("GROUP", "BY") {
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			q.GroupBy = append(q.GroupBy, e)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("ORDER", "BY") {
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := OrderItem{Expr: e}
+			if p.acceptKeyword("DESC") {
+				item.Desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			q.OrderBy = append(q.OrderBy, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		n, err := p.count("LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		q.Limit = n
+		if p.acceptKeyword("OFFSET") {
+			if q.Offset, err = p.count("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s", t)
+	}
+	return q, nil
+}
+
+// clauseKeyword reports whether a token starts a clause, and so cannot be an alias.
+func clauseKeyword(t token) bool {
+	for _, kw := range []string{"FROM", "WHERE", "GROUP", "ORDER", "LIMIT", "OFFSET"} {
+		if t.keyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) count(clause string) (int, error) {
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokNumber || err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a whole number after %s, found %s", clause, t)
+	}
+	return n, nil
+}
+
+// Operator precedence, from loosest to tightest: OR, AND, NOT, comparison,
+// additive, multiplicative, unary minus.
+
+func (p *parser) expr() (Expr, error) { return p.or() }
+
+func (p *parser) or() (Expr, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{"OR", l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	l, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		r, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{"AND", l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) not() (Expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{"NOT", x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	l, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"=", "!=", "<>", "<=", ">=", "<", ">"} {
+		if p.acceptSymbol(op) {
+			r, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			if op == "<>" {
+				op = "!="
+			}
+			return &Binary{op, l, r}, nil
+		}
+	}
+
+	switch {
+	case p.acceptKeyword("IS", "NOT", "NULL"):
+		return &IsNull{X: l, Not: true}, nil
+	case p.acceptKeyword("IS", "NULL"):
+		return &IsNull{X: l}, nil
+	}
+
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("LIKE"):
+		r, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		var e Expr = &Binary{"LIKE", l, r}
+		if not {
+			e = &Unary{"NOT", e}
+		}
+		return e, nil
+	case p.acceptKeyword("IN"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		in := &In{X: l, Not: not}
+		for {
+			e, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			in.List = append(in.List, e)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return in, nil
+	case not:
+		return nil, fmt.Errorf("expected LIKE or IN after NOT, found %s", p.peek())
+	}
+	return l, nil
+}
+
+func (p *parser) additive() (Expr, error) {
+	l, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if !op.symbol("+") && !op.symbol("-") {
+			return l, nil
+		}
+		p.next()
+		r, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{op.text, l, r}
+	}
+}
+
+func (p *parser) multiplicative() (Expr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if !op.symbol("*") && !op.symbol("/") && !op.symbol("%") {
+			return l, nil
+		}
+		p.next()
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{op.text, l, r}
+	}
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.acceptSymbol("-") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{"-", x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t)
+		}
+		return &Literal{n}, nil
+	case tokString:
+		return &Literal{t.text}, nil
+	case tokQuotedIdent:
+		return &ColumnRef{t.text}, nil
+	case tokSymbol:
+		if t.text == "(" {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expectSymbol(")")
+		}
+	case tokIdent:
+		switch strings.ToUpper(t.text) {
+		case "NULL":
+			return &Literal{nil}, nil
+		case "TRUE":
+			return &Literal{true}, nil
+		case "FALSE":
+			return &Literal{false}, nil
+		}
+		if p.acceptSymbol("(") {
+			return p.call(t)
+		}
+		return &ColumnRef{t.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *parser) call(name token) (Expr, error) {
+	c := &Call{Name: strings.ToUpper(name.text)}
+	arity, isFunc := functions[c.Name]
+	if !aggregates[c.Name] && !isFunc {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	if c.Name == "COUNT" && p.acceptSymbol("*") {
+		c.Star = true
+		return c, p.expectSymbol(")")
+	}
+	if !p.acceptSymbol(")") {
+		for {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			c.Args = append(c.Args, e)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	want := arity
+	if aggregates[c.Name] {
+		want = 1
+	}
+	if len(c.Args) != want {
+		return nil, fmt.Errorf("%s takes %d argument(s), found %d", c.Name, want, len(c.Args))
+	}
+	return c, nil
+}
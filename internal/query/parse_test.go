@@ -0,0 +1,133 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// format renders a parsed query back into text, with every clause spelled
+// out, so that tests can compare what the parser understood.
+func format(q *Query) string {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	if q.Star {
+		b.WriteString("*")
+	}
+	for i, item := range q.Items {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(item.Expr.String())
+		if item.Alias != "" {
+			b.WriteString(" AS " + item.Alias)
+		}
+	}
+	if q.Where != nil {
+		b.WriteString(" WHERE " + q.Where.String())
+	}
+	for i, e := range q.GroupBy {
+		if i == 0 {
+			b.WriteString(" GROUP BY ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(e.String())
+	}
+	for i, o := range q.OrderBy {
+		if i == 0 {
+			b.WriteString(" ORDER BY ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(o.Expr.String())
+		if o.Desc {
+			b.WriteString(" DESC")
+		}
+	}
+	if q.Limit >= 0 {
+		fmt.Fprintf(&b, " LIMIT %d", q.Limit)
+	}
+	if q.Offset > 0 {
+		fmt.Fprintf(&b, " OFFSET %d", q.Offset)
+	}
+	return b.String()
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"select *", "SELECT *"},
+		{"SELECT name, age", "SELECT name, age"},
+		{"select name n, age as years", "SELECT name AS n, age AS years"},
+		{`select "first name", ` + "`last name`" + ` as "Surname"`, `SELECT "first name", "last name" AS Surname`},
+		{"select a where b = 'it''s'", "SELECT a WHERE b = 'it''s'"},
+		{"select a where b like 'A%' and not c", "SELECT a WHERE b LIKE 'A%' AND NOT c"},
+		{"select a where b not like 'x_'", "SELECT a WHERE NOT b LIKE 'x_'"},
+		{"select a where b in (1, 2) or c not in ('x')", "SELECT a WHERE b IN (1, 2) OR c NOT IN ('x')"},
+		{"select a where b is null and c is not null", "SELECT a WHERE b IS NULL AND c IS NOT NULL"},
+		{"select a where b <> 1", "SELECT a WHERE b != 1"},
+		{"select a + b * -c", "SELECT a + b * -c"},
+		{"select count(*), sum(x), lower(name)", "SELECT count(*), sum(x), lower(name)"},
+		{"select region r, count(*) n group by r order by n desc, r", "SELECT region AS r, count(*) AS n GROUP BY r ORDER BY n DESC, r"},
+		{"select a order by a asc limit 10", "SELECT a ORDER BY a LIMIT 10"},
+		{"select a limit 5 offset 20", "SELECT a LIMIT 5 OFFSET 20"},
+		{"select a limit 0", "SELECT a LIMIT 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			q, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.src, err)
+			}
+			if got := format(q); got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"", "expected SELECT"},
+		{"select", "end of query"},
+		{"select a from sheet", "FROM is not supported"},
+		{"select a where", "end of query"},
+		{"select a limit -1", "expected a whole number after LIMIT"},
+		{"select a limit 1.5", "expected a whole number after LIMIT"},
+		{"select a limit 1 offset x", "expected a whole number after OFFSET"},
+		{"select a offset 2", "unexpected"},
+		{"select 'abc", "unterminated quote"},
+		{"select a; drop", "unexpected character"},
+		{"select a as", "expected an alias after AS"},
+		{"select count(*", `expected ")"`},
+		{"select nosuch(a)", "unknown function"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error = %v, want it to contain %q", tt.src, err, tt.want)
+			}
+		})
+	}
+}